@@ -0,0 +1,101 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/562589540/bcut-asr-go/pkg/asr"
+	"github.com/562589540/bcut-asr-go/pkg/types"
+)
+
+// runConvert 将字幕文件转换为其他格式，不访问网络
+//
+//	bcut-asr convert -i input.srt -f vtt,ass [-from srt] [-o output.vtt]
+func runConvert(args []string) {
+	fs := flag.NewFlagSet("convert", flag.ExitOnError)
+	var from string
+	fs.StringVar(&inputFile, "i", "", "输入字幕文件路径")
+	fs.StringVar(&from, "from", "", "输入格式("+strings.Join(types.OutputFormats(), "/")+")，默认按扩展名判断")
+	fs.StringVar(&outputFile, "o", "", "输出文件路径，默认与输入文件同目录")
+	fs.StringVar(&format, "f", "srt", "输出格式("+strings.Join(types.OutputFormats(), "/")+")，多个格式以逗号分隔")
+	fs.BoolVar(&words, "words", false, "输出词级时间戳(vtt/lrc)，ass 格式输出卡拉OK标签")
+	fs.Float64Var(&interval, "t", 0, "字幕断句时间间隔(秒)，0 表示保留原有断句")
+	fs.Float64Var(&maxGap, "gap", 0, "停顿超过该时长时断句(秒)，0 表示不合并中间有停顿的句子")
+	fs.IntVar(&maxChars, "chars", 0, "单条字幕最大字符数，0 表示不限制")
+	fs.Parse(args)
+
+	if inputFile == "" {
+		fmt.Fprintln(os.Stderr, "请指定输入文件路径")
+		fs.Usage()
+		os.Exit(1)
+	}
+
+	// 输入格式
+	if from == "" {
+		f, ok := types.FormatByExtension(filepath.Ext(inputFile))
+		if !ok {
+			fmt.Fprintf(os.Stderr, "无法根据扩展名判断输入格式，请通过 -from 指定: %s\n", inputFile)
+			os.Exit(1)
+		}
+		from = f.Name()
+	}
+
+	file, err := os.Open(inputFile)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "打开文件失败: %v\n", err)
+		os.Exit(1)
+	}
+	result, err := types.ParseResult(file, strings.ToLower(from))
+	file.Close()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "解析失败: %v\n", err)
+		os.Exit(1)
+	}
+
+	// 重新断句
+	if seg := segmentOptions(); !seg.IsZero() {
+		result = result.Resegment(seg)
+	}
+
+	formats := parseFormats(format)
+	for _, f := range formats {
+		if _, ok := types.LookupFormatter(f); !ok {
+			fmt.Fprintf(os.Stderr, "不支持的输出格式: %s\n", f)
+			os.Exit(1)
+		}
+	}
+	if outputFile != "" {
+		if err := os.MkdirAll(filepath.Dir(outputFile), 0755); err != nil {
+			fmt.Fprintf(os.Stderr, "创建输出目录失败: %v\n", err)
+			os.Exit(1)
+		}
+	}
+
+	paths := asr.OutputPaths(inputFile, outputFile, formats)
+	for _, path := range paths {
+		if sameFile(path, inputFile) {
+			fmt.Fprintf(os.Stderr, "输出文件与输入文件相同: %s\n", path)
+			os.Exit(1)
+		}
+	}
+	for i, path := range paths {
+		if err := asr.WriteResultFile(path, result, formats[i], formatOptions(result)); err != nil {
+			fmt.Fprintf(os.Stderr, "写入文件失败: %v\n", err)
+			os.Exit(1)
+		}
+	}
+	fmt.Fprintf(os.Stderr, "输出文件: %s\n", strings.Join(paths, ", "))
+}
+
+// sameFile 判断两个路径是否指向同一文件
+func sameFile(a, b string) bool {
+	infoA, errA := os.Stat(a)
+	infoB, errB := os.Stat(b)
+	if errA != nil || errB != nil {
+		return filepath.Clean(a) == filepath.Clean(b)
+	}
+	return os.SameFile(infoA, infoB)
+}
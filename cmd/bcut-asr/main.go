@@ -1,11 +1,13 @@
 package main
 
 import (
+	"context"
 	"flag"
 	"fmt"
 	"os"
 	"path/filepath"
 	"strings"
+	"time"
 
 	"github.com/562589540/bcut-asr-go/pkg/asr"
 	"github.com/562589540/bcut-asr-go/pkg/types"
@@ -14,28 +16,252 @@ import (
 
 var (
 	inputFile  string
+	inputFmt   string
 	outputFile string
 	format     string
+	words      bool
+	lrcTitle   string
+	lrcArtist  string
+	lrcAlbum   string
+	lrcBy      string
+	lrcOffset  int64
+	ttmlLang   string
+	frameRate  int
+	tickRate   int
 	interval   float64
+	maxGap     float64
+	maxChars   int
+	poll       float64
+	cookie     string
+	apiBaseURL string
+	uploadConc int
+	retries    int
+	journalDir string
+	cacheDir   string
+	cacheSize  int64
+	cacheAge   time.Duration
+	ffmpegPath string
+	ffprobe    string
+	chunkDur   time.Duration
+	chunkConc  int
+	trim       time.Duration
+	trimNoise  float64
 )
 
 func init() {
-	flag.StringVar(&inputFile, "i", "", "输入文件路径")
-	flag.StringVar(&outputFile, "o", "", "输出文件路径")
-	flag.StringVar(&format, "f", "srt", "输出格式(srt/lrc/txt/json)")
-	flag.Float64Var(&interval, "t", 5.0, "字幕断句时间间隔(秒)")
+	flag.StringVar(&inputFile, "i", "", "输入文件路径，- 表示从标准输入读取")
+	flag.StringVar(&inputFmt, "input-format", "", "标准输入的格式提示("+strings.Join(types.SupportedInputFormats, "/")+")，默认根据内容识别")
+	flag.StringVar(&outputFile, "o", "", "输出文件路径，- 表示输出到标准输出，从标准输入读取时默认输出到标准输出")
+	flag.StringVar(&format, "f", "srt", "输出格式("+strings.Join(types.OutputFormats(), "/")+")，多个格式以逗号分隔")
+	flag.BoolVar(&words, "words", false, "输出词级时间戳(vtt/lrc)，ass 格式输出卡拉OK标签")
+	flag.StringVar(&lrcTitle, "lrc-title", "", "lrc 歌曲名标签[ti:]")
+	flag.StringVar(&lrcArtist, "lrc-artist", "", "lrc 歌手标签[ar:]")
+	flag.StringVar(&lrcAlbum, "lrc-album", "", "lrc 专辑标签[al:]")
+	flag.StringVar(&lrcBy, "lrc-by", "", "lrc 制作者标签[by:]")
+	flag.Int64Var(&lrcOffset, "lrc-offset", 0, "lrc 时间偏移标签[offset:](毫秒)")
+	flag.StringVar(&ttmlLang, "ttml-lang", "zh", "ttml/ebuttd 字幕语言(xml:lang)")
+	flag.IntVar(&frameRate, "ttml-frame-rate", 0, "ttml 帧率，设置后时间以帧表示")
+	flag.IntVar(&tickRate, "ttml-tick-rate", 0, "ttml tick 频率，设置后时间以 tick 表示")
+	flag.Float64Var(&interval, "t", 5.0, "字幕断句时间间隔(秒)，0 表示沿用服务端断句")
+	flag.Float64Var(&maxGap, "gap", 0, "停顿超过该时长时断句(秒)，0 表示不合并中间有停顿的句子")
+	flag.IntVar(&maxChars, "chars", 0, "单条字幕最大字符数，0 表示不限制")
+	flag.Float64Var(&poll, "poll", 5.0, "查询识别结果的轮询间隔(秒)")
+	flag.StringVar(&cookie, "cookie", "", "请求接口时携带的Cookie")
+	flag.StringVar(&apiBaseURL, "api", "", "接口基础URL，默认使用官方接口")
+	flag.IntVar(&uploadConc, "upload-concurrency", 1, "同时上传的分片数")
+	flag.IntVar(&retries, "retry", asr.DefaultRetryPolicy.MaxAttempts, "每个请求最多尝试次数，1 表示不重试")
+	flag.StringVar(&journalDir, "journal", defaultCacheDir("journal"), "任务日志目录，中断后再次运行时继续上次的上传和任务，为空时不记录")
+	flag.StringVar(&cacheDir, "cache", "", "识别结果缓存目录，设置后相同音频直接使用缓存的结果而不重新识别，默认不缓存")
+	flag.Int64Var(&cacheSize, "cache-size", 512, "识别结果缓存的最大总大小(MB)，0 表示不限制")
+	flag.DurationVar(&cacheAge, "cache-age", 30*24*time.Hour, "识别结果缓存的有效期，0 表示不过期")
+	flag.StringVar(&ffmpegPath, "ffmpeg", "ffmpeg", "ffmpeg 可执行文件路径")
+	flag.StringVar(&ffprobe, "ffprobe", "ffprobe", "ffprobe 可执行文件路径，- 表示不读取媒体时长")
+	flag.DurationVar(&chunkDur, "chunk", 0, "长音频按该时长在静音处分段并行识别，如 30m，0 表示不分段")
+	flag.IntVar(&chunkConc, "chunk-concurrency", 3, "同时识别的段数")
+	flag.DurationVar(&trim, "trim", 0, "上传前去除超过该时长的静音，如 2s，0 表示不去除，字幕时间仍与原媒体对应")
+	flag.Float64Var(&trimNoise, "trim-noise", -35, "音量低于该值(dB)视为静音")
+}
+
+// defaultCacheDir 用户缓存目录下的子目录，获取失败时返回空
+func defaultCacheDir(name string) string {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(dir, "bcut-asr", name)
 }
 
 func main() {
+	// 子命令
+	if len(os.Args) > 1 {
+		switch os.Args[1] {
+		case "query":
+			runQuery(os.Args[2:])
+			return
+		case "convert":
+			runConvert(os.Args[2:])
+			return
+		}
+	}
+
 	flag.Parse()
 
 	if inputFile == "" {
-		fmt.Println("请指定输入文件路径")
+		fmt.Fprintln(os.Stderr, "请指定输入文件路径")
 		flag.Usage()
 		os.Exit(1)
 	}
+	// 从标准输入读取时默认输出到标准输出
+	if inputFile == "-" && outputFile == "" {
+		outputFile = "-"
+	}
+	formats := parseFormats(format)
+	if outputFile == "-" && len(formats) != 1 {
+		fmt.Fprintln(os.Stderr, "输出到标准输出时只能指定一种输出格式")
+		os.Exit(1)
+	}
+	for _, f := range formats {
+		if _, ok := types.LookupFormatter(f); !ok {
+			fmt.Fprintf(os.Stderr, "不支持的输出格式: %s\n", f)
+			os.Exit(1)
+		}
+	}
+
+	// 识别结果缓存
+	var cache asr.ResultCache
+	if cacheDir != "" {
+		dirCache, err := asr.NewDirCache(cacheDir, cacheSize<<20, cacheAge)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "创建缓存失败: %v\n", err)
+			os.Exit(1)
+		}
+		cache = dirCache
+	}
+
+	// 设置转换选项
+	options := asr.ConvertOptions{
+		Interval: interval,
+		Segment: &types.SegmentOptions{
+			MaxGap:   int64(maxGap * 1000),
+			MaxChars: maxChars,
+		},
+		PollInterval: poll,
+		Progress:     newProgress(),
+		Client:       newClient(),
+		JournalDir:   journalDir,
+		Cache:        cache,
+		Chunk: asr.ChunkOptions{
+			Duration:    chunkDur,
+			Concurrency: chunkConc,
+		},
+	}
+	if trim > 0 {
+		options.Trim = &asr.TrimOptions{
+			MinSilence: trim,
+			NoiseLevel: trimNoise,
+		}
+	}
 
-	// 创建进度条
+	// 执行识别
+	var (
+		result *types.ASRResult
+		err    error
+	)
+	if inputFile == "-" {
+		result, err = asr.TranscribeReader(context.Background(), os.Stdin, "stdin", inputFmt, options)
+	} else {
+		result, err = asr.Transcribe(context.Background(), inputFile, options)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "\n转换失败: %v\n", err)
+		os.Exit(1)
+	}
+
+	// 输出结果
+	if outputFile == "-" {
+		fmt.Fprintln(os.Stderr)
+		if err := asr.WriteResult(os.Stdout, result, formats[0], formatOptions(result)); err != nil {
+			fmt.Fprintf(os.Stderr, "输出失败: %v\n", err)
+			os.Exit(1)
+		}
+		return
+	}
+	paths, err := asr.WriteResultFiles(result, inputFile, outputFile, formats, formatOptions(result))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "\n转换失败: %v\n", err)
+		os.Exit(1)
+	}
+	fmt.Fprintf(os.Stderr, "\n转换完成！输出文件: %s\n", strings.Join(paths, ", "))
+}
+
+// segmentOptions 根据命令行参数生成断句选项
+func segmentOptions() types.SegmentOptions {
+	return types.SegmentOptions{
+		MaxDuration: int64(interval * 1000),
+		MaxGap:      int64(maxGap * 1000),
+		MaxChars:    maxChars,
+	}
+}
+
+// parseFormats 解析以逗号分隔的输出格式
+func parseFormats(s string) []string {
+	var formats []string
+	for _, format := range strings.Split(s, ",") {
+		if format = strings.ToLower(strings.TrimSpace(format)); format != "" {
+			formats = append(formats, format)
+		}
+	}
+	return formats
+}
+
+// formatOptions 根据命令行参数生成输出格式选项，lrc 的 [length:] 取 result 最后一句的结束时间
+func formatOptions(result *types.ASRResult) types.FormatOptions {
+	var length int64
+	for _, u := range result.Utterances {
+		if u.EndTime > length {
+			length = u.EndTime
+		}
+	}
+	return types.FormatOptions{
+		VTT: types.VTTOptions{WordTimestamps: words},
+		ASS: types.ASSOptions{Karaoke: words},
+		LRC: types.LRCOptions{
+			WordTimestamps: words,
+			Title:          lrcTitle,
+			Artist:         lrcArtist,
+			Album:          lrcAlbum,
+			By:             lrcBy,
+			Length:         length,
+			Offset:         lrcOffset,
+		},
+		TTML: types.TTMLOptions{
+			Lang:      ttmlLang,
+			FrameRate: frameRate,
+			TickRate:  tickRate,
+		},
+	}
+}
+
+// newClient 根据命令行参数创建客户端
+func newClient() *asr.Client {
+	retryPolicy := asr.DefaultRetryPolicy
+	retryPolicy.MaxAttempts = retries
+	clientOpts := []asr.Option{
+		asr.WithUploadConcurrency(uploadConc),
+		asr.WithRetryPolicy(retryPolicy),
+		asr.WithTranscoder(&asr.FFmpegTranscoder{FFmpeg: ffmpegPath, FFprobe: ffprobe}),
+	}
+	if apiBaseURL != "" {
+		clientOpts = append(clientOpts, asr.WithBaseURL(apiBaseURL))
+	}
+	if cookie != "" {
+		clientOpts = append(clientOpts, asr.WithCookie(cookie))
+	}
+	return asr.NewClient(clientOpts...)
+}
+
+// newProgress 创建按阶段显示进度条的进度回调
+func newProgress() types.ProgressCallback {
 	var (
 		bar       *progressbar.ProgressBar
 		lastStage types.ProgressStage
@@ -45,6 +271,7 @@ func main() {
 	newBar := func(msg string) *progressbar.ProgressBar {
 		return progressbar.NewOptions64(100,
 			progressbar.OptionFullWidth(),
+			progressbar.OptionSetWriter(os.Stderr),
 			progressbar.OptionSetDescription(msg),
 			progressbar.OptionSetTheme(progressbar.Theme{
 				Saucer:        "=",
@@ -56,21 +283,9 @@ func main() {
 		)
 	}
 
-	// 创建进度回调
-	progress := func(info types.ProgressInfo) {
+	return func(info types.ProgressInfo) {
 		// 生成新的消息
-		prefix := ""
-		switch info.Stage {
-		case types.StageInit:
-			prefix = "初始化"
-		case types.StageUpload:
-			prefix = "上传中"
-		case types.StageProcess:
-			prefix = "识别中"
-		case types.StageComplete:
-			prefix = "完成"
-		}
-		msg := fmt.Sprintf("[%s] %s", prefix, info.Description)
+		msg := fmt.Sprintf("[%s] %s", types.ProgressStageCN(info.Stage), info.Description)
 
 		// 只在阶段变化时创建新进度条
 		if info.Stage != lastStage || bar == nil {
@@ -79,7 +294,7 @@ func main() {
 				if lastStage != types.StageComplete {
 					_ = bar.Set(100)
 				}
-				fmt.Println() // 换行，保留旧进度条
+				fmt.Fprintln(os.Stderr) // 换行，保留旧进度条
 			}
 			bar = newBar(msg)
 			lastStage = info.Stage
@@ -91,34 +306,4 @@ func main() {
 		// 更新进度
 		_ = bar.Set(info.Current)
 	}
-
-	// 设置转换选项
-	options := asr.ConvertOptions{
-		Format:     strings.ToLower(format),
-		Interval:   interval,
-		Progress:   progress,
-		OutputPath: outputFile,
-	}
-
-	// 执行转换
-	if err := asr.ConvertToSubtitle(inputFile, options); err != nil {
-		fmt.Printf("\n转换失败: %v\n", err)
-		os.Exit(1)
-	}
-
-	fmt.Printf("\n转换完成！输出文件: %s\n", getOutputPath(inputFile, outputFile, format))
-}
-
-func getOutputPath(inputFile, outputFile, format string) string {
-	if outputFile != "" {
-		if info, err := os.Stat(outputFile); err == nil && info.IsDir() {
-			return filepath.Join(outputFile,
-				filepath.Base(inputFile[:len(inputFile)-len(filepath.Ext(inputFile))])+"."+format)
-		}
-		return outputFile
-	}
-	return filepath.Join(
-		filepath.Dir(inputFile),
-		filepath.Base(inputFile[:len(inputFile)-len(filepath.Ext(inputFile))])+"."+format,
-	)
 }
@@ -0,0 +1,100 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/562589540/bcut-asr-go/pkg/asr"
+	"github.com/562589540/bcut-asr-go/pkg/types"
+)
+
+// runQuery 查询已有任务的结果，或为已上传的资源创建任务，不重新上传文件
+//
+//	bcut-asr query -task <任务ID> [-f srt] [-o output.srt]
+//	bcut-asr query -resource <资源地址> [-f srt] [-o output.srt]
+func runQuery(args []string) {
+	fs := flag.NewFlagSet("query", flag.ExitOnError)
+	var (
+		taskID   string
+		resource string
+		noWait   bool
+	)
+	fs.StringVar(&taskID, "task", "", "任务ID")
+	fs.StringVar(&resource, "resource", "", "已上传资源的地址，为其创建新任务")
+	fs.BoolVar(&noWait, "no-wait", false, "只查询一次，任务未完成时立即退出")
+	fs.StringVar(&outputFile, "o", "", "输出文件路径，默认输出到标准输出")
+	fs.StringVar(&format, "f", "srt", "输出格式("+strings.Join(types.OutputFormats(), "/")+")")
+	fs.BoolVar(&words, "words", false, "输出词级时间戳(vtt/lrc)，ass 格式输出卡拉OK标签")
+	fs.Float64Var(&interval, "t", 5.0, "字幕断句时间间隔(秒)，0 表示沿用服务端断句")
+	fs.Float64Var(&maxGap, "gap", 0, "停顿超过该时长时断句(秒)，0 表示不合并中间有停顿的句子")
+	fs.IntVar(&maxChars, "chars", 0, "单条字幕最大字符数，0 表示不限制")
+	fs.Float64Var(&poll, "poll", 5.0, "查询识别结果的轮询间隔(秒)")
+	fs.StringVar(&cookie, "cookie", "", "请求接口时携带的Cookie")
+	fs.StringVar(&apiBaseURL, "api", "", "接口基础URL，默认使用官方接口")
+	fs.IntVar(&retries, "retry", asr.DefaultRetryPolicy.MaxAttempts, "每个请求最多尝试次数，1 表示不重试")
+	fs.Parse(args)
+
+	if (taskID == "") == (resource == "") {
+		fmt.Fprintln(os.Stderr, "请指定 -task 或 -resource 其中之一")
+		fs.Usage()
+		os.Exit(1)
+	}
+
+	ctx := context.Background()
+	client := newClient()
+
+	if resource != "" {
+		id, err := client.CreateTask(ctx, resource)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "创建任务失败: %v\n", err)
+			os.Exit(1)
+		}
+		taskID = id
+		fmt.Fprintf(os.Stderr, "任务已创建: %s\n", taskID)
+	}
+
+	var (
+		result *types.ASRResult
+		err    error
+	)
+	if noWait {
+		result, err = client.QueryTask(ctx, taskID)
+	} else {
+		result, err = client.WaitTask(ctx, taskID, time.Duration(poll*float64(time.Second)), nil)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "查询失败: %v\n", err)
+		os.Exit(1)
+	}
+	if result == nil {
+		fmt.Fprintf(os.Stderr, "任务 %s 尚未完成\n", taskID)
+		os.Exit(2)
+	}
+
+	// 重新断句
+	if seg := segmentOptions(); !seg.IsZero() {
+		result = result.Resegment(seg)
+	}
+
+	if outputFile == "" {
+		if err := asr.WriteResult(os.Stdout, result, strings.ToLower(format), formatOptions(result)); err != nil {
+			fmt.Fprintf(os.Stderr, "转换失败: %v\n", err)
+			os.Exit(1)
+		}
+		return
+	}
+	if err := os.MkdirAll(filepath.Dir(outputFile), 0755); err != nil {
+		fmt.Fprintf(os.Stderr, "创建输出目录失败: %v\n", err)
+		os.Exit(1)
+	}
+	if err := asr.WriteResultFile(outputFile, result, strings.ToLower(format), formatOptions(result)); err != nil {
+		fmt.Fprintf(os.Stderr, "写入文件失败: %v\n", err)
+		os.Exit(1)
+	}
+	fmt.Fprintf(os.Stderr, "输出文件: %s\n", outputFile)
+}
@@ -5,406 +5,717 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
-	"net/http"
-	"net/url"
+	"io"
 	"os"
 	"path/filepath"
-	"strconv"
 	"strings"
 	"time"
 
 	"github.com/562589540/bcut-asr-go/pkg/types"
-	"github.com/562589540/bcut-asr-go/pkg/utils"
 )
 
-type BcutASR struct {
-	client      *http.Client
+// Job 单个文件的识别任务：加载音频 → 上传 → 创建任务 → 查询结果
+//
+// Job 由 Client.NewJob 创建，保存当前文件的上传和任务状态，不能在多个 goroutine 中同时使用。
+// 通过 SetData 加载的文件在 Close 之前保持打开。
+type Job struct {
+	client      *Client
 	soundName   string
-	soundData   []byte
 	soundFormat string
-	inBossKey   string
-	resourceID  string
-	uploadID    string
-	uploadURLs  []string
-	perSize     int
-	etags       []string
+	soundSize   int64
+	source      io.ReaderAt  // 可随机读取的音频数据
+	stream      io.Reader    // 只能顺序读取的音频数据，source 为空时使用
+	cleanup     func() error // 释放 SetData 打开的文件或临时文件
 	downloadURL string
 	taskID      string
+	journal     *Journal
+	journalKey  string
+	state       *JobState // 当前音频各步骤的结果，设置 journal 时持久化
+	resumedURL  bool      // downloadURL 来自任务日志
+	resumedTask bool      // taskID 来自任务日志
+	mediaInfo   *types.MediaInfo
+	timeMap     types.TimeMap // 去除静音后的时间映射
 	onProgress  types.ProgressCallback
 	ctx         context.Context
 }
 
-func New(ctx context.Context, cookie ...string) *BcutASR {
-	if ctx == nil {
-		ctx = context.Background()
-	}
-	b := &BcutASR{
-		client: &http.Client{},
-		etags:  make([]string, 0),
-		ctx:    ctx,
+// BcutASR 兼容旧版本的名称
+//
+// Deprecated: 使用 NewClient 创建 Client，再通过 Client.NewJob 创建 Job。
+type BcutASR = Job
+
+// New 使用默认客户端创建识别任务，cookie 可选
+func New(ctx context.Context, cookie ...string) *Job {
+	var opts []Option
+	if len(cookie) > 0 && cookie[0] != "" {
+		opts = append(opts, WithCookie(cookie[0]))
 	}
-	return b
+	return NewClient(opts...).NewJob(ctx)
 }
 
-func (b *BcutASR) processMedia(filePath string) error {
-	ext := strings.ToLower(filepath.Ext(filePath))
+func (j *Job) processMedia(filePath string) error {
+	if j.onProgress != nil {
+		j.reportProgress(types.StageInit, 0, "读取音频文件...")
+	}
+	file, err := os.Open(filePath)
+	if err != nil {
+		return err
+	}
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return err
+	}
+	if info.Size() == 0 {
+		file.Close()
+		return ErrNoAudio
+	}
 
-	// 检查是否是支持的音频格式
-	for _, format := range types.SupportedInputFormats {
-		if "."+format == ext {
-			// 直接读取音��文件
-			if b.onProgress != nil {
-				b.reportProgress(types.StageInit, 0, "读取音频文件...")
-			}
-			data, err := os.ReadFile(filePath)
-			if err != nil {
-				return err
-			}
-			b.soundData = data
-			b.soundName = filepath.Base(filePath)
-			b.soundFormat = format
-			return nil
-		}
+	// 根据文件头判断格式，扩展名只作为参考
+	head := make([]byte, sniffLen)
+	n, err := file.ReadAt(head, 0)
+	if err != nil && err != io.EOF {
+		file.Close()
+		return err
+	}
+	ext := strings.ToLower(strings.TrimPrefix(filepath.Ext(filePath), "."))
+	format, err := uploadFormat(head[:n], ext)
+	if err != nil {
+		file.Close()
+		return fmt.Errorf("%s: %w", filepath.Base(filePath), err)
+	}
+
+	name := strings.TrimSuffix(filepath.Base(filePath), filepath.Ext(filePath))
+	if format != "" {
+		// 直接上传音频文件，上传时按分片读取
+		j.SetReaderAt(file, info.Size(), name+"."+format, format)
+		j.cleanup = file.Close
+		return nil
 	}
 
 	// 不是支持的音频格式，尝试用ffmpeg提取音频
-	b.reportProgress(types.StageInit, 20, "准备提取音频...")
-
-	// 准备命令
-	cmd := utils.RunCommand("ffmpeg",
-		"-v", "warning",
-		"-i", filePath,
-		"-ac", "1",
-		"-acodec", "aac",
-		"-ar", "16000",
-		//"-ab", "32k",
-		"-f", "adts",
-		"-",
-	)
-
-	// 创建缓冲区
-	var buf bytes.Buffer
-	cmd.Stdout = &buf
+	file.Close()
+	return j.transcode(TranscodeInput{Path: filePath}, name)
+}
 
-	// 创建stderr管道用于进度监控
-	stderr, err := cmd.StderrPipe()
+// transcode 使用客户端的 Transcoder 提取音频，name 为不含扩展名的上传文件名
+func (j *Job) transcode(in TranscodeInput, name string) error {
+	in.Progress = func(current int, description string) {
+		j.reportProgress(types.StageInit, current, description)
+	}
+	out, err := j.client.transcoder.Transcode(j.ctx, in)
 	if err != nil {
-		return fmt.Errorf("创建stderr管道失败: %w", err)
+		return err
+	}
+	if err := j.setTranscoded(out, name); err != nil {
+		return err
 	}
 
-	// 启动进度监控
-	go func() {
-		scanner := bufio.NewScanner(stderr)
-		for scanner.Scan() {
-			line := scanner.Text()
-			if strings.Contains(line, "time=") {
-				b.reportProgress(types.StageInit, 50, "音频提取中")
-			}
-		}
-	}()
+	j.reportProgress(types.StageInit, 100, "音频提取完成")
+	return nil
+}
 
-	// 执行命令
-	b.reportProgress(types.StageInit, 40, "开始提取音频...")
-	if err := cmd.Run(); err != nil {
-		return fmt.Errorf("ffmpeg执行失败: %w", err)
+// setTranscoded 设置 Transcoder 输出的音频，失败时关闭 out.Audio
+func (j *Job) setTranscoded(out *TranscodeOutput, name string) error {
+	format := strings.ToLower(strings.TrimPrefix(out.Format, "."))
+	if !isSupportedInputFormat(format) {
+		out.Audio.Close()
+		return fmt.Errorf("转码输出的音频格式不支持上传: %q", out.Format)
 	}
 
-	// 保存结果
-	b.soundData = buf.Bytes()
-	if len(b.soundData) == 0 {
-		return fmt.Errorf("未提取到音频数据")
+	audio, size := out.Audio, out.Size
+	if size < 0 {
+		// 大小未知，写入临时文件
+		tmp, n, err := spoolTempFile(audio, format)
+		audio.Close()
+		if err != nil {
+			return err
+		}
+		audio, size = tmp, n
+	}
+	if size == 0 {
+		audio.Close()
+		return ErrNoAudio
 	}
 
-	b.soundName = strings.TrimSuffix(filepath.Base(filePath), filepath.Ext(filePath)) + ".aac"
-	b.soundFormat = "aac"
-
-	b.reportProgress(types.StageInit, 100, "音频提取完成")
+	if r, ok := audio.(io.ReaderAt); ok {
+		j.SetReaderAt(r, size, name+"."+format, format)
+	} else {
+		j.SetReader(audio, size, name+"."+format, format)
+	}
+	j.cleanup = audio.Close
+	j.mediaInfo = out.Info
 	return nil
 }
 
-func (b *BcutASR) SetData(filePath string) error {
-	if b.onProgress != nil {
-		b.reportProgress(types.StageInit, 0, "开始加载文件...")
+func (j *Job) SetData(filePath string) error {
+	if err := j.Close(); err != nil {
+		return err
+	}
+
+	if j.onProgress != nil {
+		j.reportProgress(types.StageInit, 0, "开始加载文件...")
 	}
-	err := b.processMedia(filePath)
+	err := j.processMedia(filePath)
 	if err == nil {
 		return nil
 	}
 	return err
 }
 
-func (b *BcutASR) Upload() error {
-	// 1. 申请上传
-	formData := url.Values{
-		"type":               {"2"},
-		"name":               {b.soundName},
-		"size":               {strconv.Itoa(len(b.soundData))},
-		"resource_file_type": {b.soundFormat},
-		"model_id":           {"7"},
+// SetDataFromReader 从 r 加载音视频，用于标准输入等没有文件路径的输入，name 为上传时的文件名
+//
+// 根据内容开头识别格式，format 为可选的格式提示。可以直接上传的音频先写入临时文件以获得大小，
+// 原样上传；其他音视频由客户端的 Transcoder 从 r 读取并提取音频。
+func (j *Job) SetDataFromReader(r io.Reader, name, format string) error {
+	if err := j.Close(); err != nil {
+		return err
 	}
 
-	req, err := http.NewRequestWithContext(b.ctx, http.MethodPost, types.GetAPIBaseURL()+types.APIReqUpload, strings.NewReader(formData.Encode()))
-	if err != nil {
-		return fmt.Errorf("create request failed: %w", err)
+	j.reportProgress(types.StageInit, 0, "开始读取输入...")
+	br := bufio.NewReaderSize(r, sniffLen)
+	head, err := br.Peek(sniffLen)
+	if err != nil && err != io.EOF {
+		return fmt.Errorf("读取输入失败: %w", err)
+	}
+	if len(head) == 0 {
+		return ErrNoAudio
 	}
-	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
 
-	resp, err := b.client.Do(req)
+	format, err = uploadFormat(head, strings.ToLower(strings.TrimPrefix(format, ".")))
 	if err != nil {
-		return fmt.Errorf("request upload failed: %w", err)
+		return err
+	}
+	name = strings.TrimSuffix(name, filepath.Ext(name))
+	if format == "" {
+		return j.transcode(TranscodeInput{Reader: br}, name)
 	}
-	defer resp.Body.Close()
 
-	var result types.ASRResponse
-	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
-		return fmt.Errorf("decode response failed: %w", err)
+	tmp, size, err := spoolTempFile(br, format)
+	if err != nil {
+		return err
 	}
+	j.SetReaderAt(tmp, size, name+"."+format, format)
+	j.cleanup = tmp.Close
+	return nil
+}
 
-	if result.Code != 0 {
-		return fmt.Errorf("API error: %d - %s", result.Code, result.Message)
+// isSupportedInputFormat 是否为可以直接上传的音频格式
+func isSupportedInputFormat(format string) bool {
+	for _, f := range types.SupportedInputFormats {
+		if f == format {
+			return true
+		}
 	}
+	return false
+}
+
+// SetReaderAt 设置可随机读取的音频数据，上传时每个分片直接从 r 读取
+//
+// format 为 types.SupportedInputFormats 中的格式，调用方负责在上传完成前保持 r 可用。
+func (j *Job) SetReaderAt(r io.ReaderAt, size int64, name, format string) {
+	j.reset()
+	j.source = r
+	j.soundSize = size
+	j.soundName = name
+	j.soundFormat = format
+}
 
-	var createResp types.ResourceCreateResponse
-	createRespData, err := json.Marshal(result.Data)
-	if err := json.Unmarshal(createRespData, &createResp); err != nil {
-		return fmt.Errorf("parse create response failed: %w", err)
+// SetReader 设置只能顺序读取的音频数据，size 必须与实际长度一致
+//
+// 上传时依次读取每个分片，内存占用不超过一个分片的大小，同一数据只能上传一次。
+func (j *Job) SetReader(r io.Reader, size int64, name, format string) {
+	j.reset()
+	j.stream = r
+	j.soundSize = size
+	j.soundName = name
+	j.soundFormat = format
+}
+
+// Close 释放 SetData 打开的文件和临时文件
+func (j *Job) Close() error {
+	var err error
+	if j.cleanup != nil {
+		err = j.cleanup()
+		j.cleanup = nil
 	}
+	j.reset()
+	return err
+}
 
-	b.inBossKey = createResp.InBossKey
-	b.resourceID = createResp.ResourceID
-	b.uploadID = createResp.UploadID
-	b.uploadURLs = createResp.UploadURLs
-	b.perSize = createResp.PerSize
+// reset 清除上一个文件的数据、上传和任务状态
+func (j *Job) reset() {
+	j.source = nil
+	j.stream = nil
+	j.soundSize = 0
+	j.soundName = ""
+	j.soundFormat = ""
+	j.downloadURL = ""
+	j.taskID = ""
+	j.state = nil
+	j.resumedURL = false
+	j.resumedTask = false
+	j.mediaInfo = nil
+	j.timeMap = nil
+}
 
-	// 2. 分片上传
-	if err := b.uploadParts(); err != nil {
-		return fmt.Errorf("upload parts failed: %w", err)
+// MediaInfo 返回提取音频时 Transcoder 读取的媒体信息
+//
+// 默认的 FFmpegTranscoder 只在输入为文件且 ffprobe 可用时读取，其他情况返回 nil。
+func (j *Job) MediaInfo() *types.MediaInfo {
+	return j.mediaInfo
+}
+
+func (j *Job) Upload() error {
+	if j.source == nil && j.stream == nil {
+		return ErrNoAudio
 	}
 
-	// 3. 提交上传
-	if err := b.commitUpload(); err != nil {
-		return fmt.Errorf("commit upload failed: %w", err)
+	state, err := j.loadState()
+	if err != nil {
+		return err
 	}
+	j.state = state
 
-	return nil
-}
+	// 上次已完成上传
+	if state.DownloadURL != "" {
+		j.downloadURL = state.DownloadURL
+		j.taskID = state.TaskID
+		j.resumedURL = true
+		j.reportProgress(types.StageUpload, 100, "已上传，跳过上传")
+		return nil
+	}
 
-func (b *BcutASR) uploadParts() error {
-	totalParts := len(b.uploadURLs)
-	for i, url := range b.uploadURLs {
-		select {
-		case <-b.ctx.Done():
-			return b.ctx.Err()
-		default:
-		}
+	resumed := state.resource() != nil
+	err = j.upload()
+	// 续传失败时（如上传地址已过期）重新申请上传
+	if err != nil && resumed && j.source != nil && j.ctx.Err() == nil {
+		j.state = j.newState()
+		err = j.upload()
+	}
+	return err
+}
 
-		// 计算上传进度 (0-90%)
-		progress := (i + 1) * 90 / totalParts
-		b.reportProgress(
-			types.StageUpload,
-			progress,
-			fmt.Sprintf("正在上传分片 %d/%d", i+1, totalParts),
-		)
-
-		start := i * b.perSize
-		end := start + b.perSize
-		if end > len(b.soundData) {
-			end = len(b.soundData)
-		}
+// upload 从 j.state 记录的步骤继续上传
+func (j *Job) upload() error {
+	state := j.state
 
-		req, err := http.NewRequestWithContext(b.ctx, http.MethodPut, url, bytes.NewReader(b.soundData[start:end]))
+	// 1. 申请上传
+	resource := state.resource()
+	if resource == nil {
+		var err error
+		resource, err = j.client.createResource(j.ctx, j.soundName, j.soundFormat, j.soundSize)
 		if err != nil {
-			return err
+			return fmt.Errorf("request upload failed: %w", err)
 		}
-
-		resp, err := b.client.Do(req)
-		if err != nil {
+		state.setResource(resource)
+		if err := j.saveState(); err != nil {
 			return err
 		}
-		resp.Body.Close()
+	} else if len(state.ETags) != len(resource.UploadURLs) {
+		state.ETags = make([]string, len(resource.UploadURLs))
+	}
 
-		etag := resp.Header.Get("Etag")
-		if etag == "" {
-			return fmt.Errorf("no etag in response for part %d", i)
-		}
-		b.etags = append(b.etags, etag)
+	// 2. 分片上传，跳过已完成的分片
+	etags, err := j.uploadParts(resource, state.ETags, func(part int, etag string) error {
+		state.ETags[part] = etag
+		return j.saveState()
+	})
+	if err != nil {
+		return fmt.Errorf("upload parts failed: %w", err)
+	}
+
+	// 3. 提交上传
+	complete, err := j.client.commitResource(j.ctx, resource, etags)
+	if err != nil {
+		return fmt.Errorf("commit upload failed: %w", err)
+	}
+
+	state.DownloadURL = complete.DownloadURL
+	if err := j.saveState(); err != nil {
+		return err
 	}
 
-	// 最后一次性报告100%
-	b.reportProgress(types.StageUpload, 100, "上传完成")
+	j.downloadURL = complete.DownloadURL
+	j.taskID = ""
+	j.resumedURL = false
 	return nil
 }
 
-func (b *BcutASR) commitUpload() error {
-	formData := url.Values{
-		"in_boss_key": {b.inBossKey},
-		"resource_id": {b.resourceID},
-		"etags":       {strings.Join(b.etags, ",")},
-		"upload_id":   {b.uploadID},
-		"model_id":    {"7"},
+func (j *Job) CreateTask() (string, error) {
+	// 上次已创建任务
+	if j.state != nil && j.state.TaskID != "" && j.state.DownloadURL == j.downloadURL {
+		j.taskID = j.state.TaskID
+		j.resumedTask = true
+		return j.taskID, nil
 	}
 
-	req, err := http.NewRequestWithContext(b.ctx, http.MethodPost, types.GetAPIBaseURL()+types.APICommitUpload, strings.NewReader(formData.Encode()))
+	taskID, err := j.client.CreateTask(j.ctx, j.downloadURL)
+	if err != nil && j.resumedURL && j.state != nil && j.client.rejected(err) {
+		// 任务日志中的资源已失效（如已过期），清除后重新上传
+		j.state = j.newState()
+		if err := j.saveState(); err != nil {
+			return "", err
+		}
+		if err := j.upload(); err != nil {
+			return "", err
+		}
+		taskID, err = j.client.CreateTask(j.ctx, j.downloadURL)
+	}
 	if err != nil {
-		return fmt.Errorf("create request failed: %w", err)
+		return "", err
+	}
+
+	j.taskID = taskID
+	j.resumedTask = false
+	if j.state != nil {
+		j.state.TaskID = taskID
+		if err := j.saveState(); err != nil {
+			return "", err
+		}
 	}
-	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	return taskID, nil
+}
 
-	resp, err := b.client.Do(req)
+// QueryResult 查询一次任务结果，任务未完成时返回 nil, nil
+//
+// 从任务日志恢复的任务被服务端拒绝（如已过期）时重新创建任务，同样返回 nil, nil。
+func (j *Job) QueryResult() (*types.ASRResult, error) {
+	result, err := j.client.queryResult(j.ctx, j.taskID, j.onProgress)
 	if err != nil {
-		return err
+		var recreated bool
+		if recreated, err = j.recreateTask(err); recreated {
+			return nil, nil
+		}
 	}
-	defer resp.Body.Close()
+	return result, j.checkTaskError(err)
+}
 
-	var result types.ASRResponse
-	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
-		return err
+// Wait 每隔 interval 查询一次任务结果，直到任务完成、失败或上下文取消
+//
+// 从任务日志恢复的任务被服务端拒绝（如已过期）时重新创建任务并继续等待。
+func (j *Job) Wait(interval time.Duration) (*types.ASRResult, error) {
+	result, err := j.client.WaitTask(j.ctx, j.taskID, interval, j.onProgress)
+	if err != nil {
+		var recreated bool
+		if recreated, err = j.recreateTask(err); recreated {
+			result, err = j.client.WaitTask(j.ctx, j.taskID, interval, j.onProgress)
+		}
 	}
+	return result, j.checkTaskError(err)
+}
 
-	if result.Code != 0 {
-		return fmt.Errorf("API error: %d - %s", result.Code, result.Message)
+// recreateTask 从任务日志恢复的任务被服务端拒绝时，清除记录的任务ID并重新创建任务
+//
+// 返回是否已重新创建；不需要或无法重新创建时返回原来的错误或创建任务的错误。
+func (j *Job) recreateTask(err error) (bool, error) {
+	if !j.resumedTask || j.state == nil || !j.client.rejected(err) {
+		return false, err
 	}
+	j.state.TaskID = ""
+	if saveErr := j.saveState(); saveErr != nil {
+		return false, errors.Join(err, saveErr)
+	}
+	if _, err := j.CreateTask(); err != nil {
+		return false, err
+	}
+	return true, nil
+}
 
-	var completeResp types.ResourceCompleteResponse
-	completeRespData, err := json.Marshal(result.Data)
-	if err := json.Unmarshal(completeRespData, &completeResp); err != nil {
-		return err
+// checkTaskError 任务失败时清除记录的任务ID，下次运行重新创建任务
+func (j *Job) checkTaskError(err error) error {
+	var taskErr *TaskFailedError
+	if j.state != nil && errors.As(err, &taskErr) {
+		j.state.TaskID = ""
+		if saveErr := j.saveState(); saveErr != nil {
+			return errors.Join(err, saveErr)
+		}
 	}
+	return err
+}
 
-	b.downloadURL = completeResp.DownloadURL
-	return nil
+// DownloadURL 返回上传完成后的资源地址
+func (j *Job) DownloadURL() string {
+	return j.downloadURL
 }
 
-func (b *BcutASR) CreateTask() (string, error) {
-	reqData := map[string]interface{}{
-		"resource": b.downloadURL,
-		"model_id": "7",
+// TaskID 返回已创建的任务ID
+func (j *Job) TaskID() string {
+	return j.taskID
+}
+
+func (j *Job) WithProgress(callback types.ProgressCallback) *Job {
+	j.onProgress = callback
+	return j
+}
+
+func (j *Job) reportProgress(stage types.ProgressStage, current int, description string) {
+	reportProgress(j.onProgress, stage, current, description)
+}
+
+// reportProgress 调用进度回调，callback 可以为空
+func reportProgress(callback types.ProgressCallback, stage types.ProgressStage, current int, description string) {
+	if callback != nil {
+		callback(types.ProgressInfo{
+			Stage:       stage,
+			Total:       100,
+			Current:     current,
+			Description: description,
+		})
 	}
+}
 
-	reqBody, err := json.Marshal(reqData)
-	if err != nil {
-		return "", err
+// ConvertOptions 转换选项
+type ConvertOptions struct {
+	Format        string                 // 输出格式，默认 "srt"
+	Formats       []string               // 输出格式列表，可选，设置后忽略 Format，一次识别输出多个文件
+	FormatOptions types.FormatOptions    // 输出格式选项，可选
+	Interval      float64                // 字幕断句时间间隔（秒），即单条字幕最长时长，0 表示沿用服务端断句
+	Segment       *types.SegmentOptions  // 断句选项，可选，未设置 MaxDuration 时使用 Interval
+	PollInterval  float64                // 轮询间隔（秒），默认 30.0
+	Progress      types.ProgressCallback // 进度回调，可选
+	OutputPath    string                 // 输出路径，可选，默认与输入文件同目录
+	Context       context.Context        // 上下文，可选，用于取消操作
+	Client        *Client                // 客户端，可选，默认使用 NewClient()
+	JournalDir    string                 // 任务日志目录，可选，设置后中断的转换再次运行时从上次完成的步骤继续
+	Cache         ResultCache            // 识别结果缓存，可选，相同音频和模型直接使用缓存的结果
+	Chunk         ChunkOptions           // 长音频分段识别选项，可选，默认不分段
+	Trim          *TrimOptions           // 上传前去除长静音，可选，默认不去除
+}
+
+// DefaultConvertOptions 默认转换选项
+var DefaultConvertOptions = ConvertOptions{
+	Format:       "srt",
+	PollInterval: 30.0,
+}
+
+// segmentOptions 合并 Interval 与 Segment 得到最终断句选项
+func (o ConvertOptions) segmentOptions() types.SegmentOptions {
+	var seg types.SegmentOptions
+	if o.Segment != nil {
+		seg = *o.Segment
 	}
+	if seg.MaxDuration <= 0 && o.Interval > 0 {
+		seg.MaxDuration = int64(o.Interval * 1000)
+	}
+	return seg
+}
 
-	req, err := http.NewRequestWithContext(b.ctx, http.MethodPost,
-		types.GetAPIBaseURL()+types.APICreateTask,
-		bytes.NewReader(reqBody))
-	if err != nil {
-		return "", fmt.Errorf("create request failed: %w", err)
+// formats 去重后的输出格式列表
+func (o ConvertOptions) formats() []string {
+	if len(o.Formats) == 0 {
+		return []string{o.Format}
+	}
+	var formats []string
+	seen := make(map[string]bool)
+	for _, format := range o.Formats {
+		format = strings.ToLower(strings.TrimSpace(format))
+		if format == "" || seen[format] {
+			continue
+		}
+		seen[format] = true
+		formats = append(formats, format)
 	}
+	return formats
+}
 
-	req.Header.Set("Content-Type", "application/json")
-	resp, err := b.client.Do(req)
-	if err != nil {
-		return "", err
+// OutputPaths 返回每种输出格式的文件路径，与 ConvertToSubtitle 的规则相同：
+// outputPath 为空时与输入文件同目录；为已存在的目录时在该目录下以输入文件名命名；
+// 否则为文件路径，输出多种格式时将其扩展名替换为各格式的扩展名。
+func OutputPaths(inputFile, outputPath string, formats []string) []string {
+	base := filepath.Base(inputFile[:len(inputFile)-len(filepath.Ext(inputFile))])
+	dir := filepath.Dir(inputFile)
+	if outputPath != "" {
+		if info, err := os.Stat(outputPath); err == nil && info.IsDir() {
+			dir = outputPath
+		} else if len(formats) == 1 {
+			return []string{outputPath}
+		} else {
+			dir = filepath.Dir(outputPath)
+			base = filepath.Base(outputPath[:len(outputPath)-len(filepath.Ext(outputPath))])
+		}
 	}
-	defer resp.Body.Close()
 
-	var result types.ASRResponse
-	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
-		return "", err
+	paths := make([]string, len(formats))
+	for i, format := range formats {
+		paths[i] = filepath.Join(dir, base+"."+types.FormatExtension(format))
 	}
+	return paths
+}
 
-	if result.Code != 0 {
-		return "", fmt.Errorf("API error: %d - %s", result.Code, result.Message)
+// withDefaults 填充未设置的选项
+func (o ConvertOptions) withDefaults() ConvertOptions {
+	// 确保格式有值
+	if o.Format == "" {
+		o.Format = "srt"
+	}
+	// 确保轮询间隔有值
+	if o.PollInterval <= 0 {
+		o.PollInterval = 30.0
+	}
+	// 确保上下文有值
+	if o.Context == nil {
+		o.Context = context.Background()
 	}
+	// 确保客户端有值
+	if o.Client == nil {
+		o.Client = NewClient()
+	}
+	return o
+}
 
-	var taskResp types.TaskCreateResponse
-	taskRespData, err := json.Marshal(result.Data)
-	if err := json.Unmarshal(taskRespData, &taskResp); err != nil {
-		return "", err
+// Transcribe 识别音视频文件，返回按断句选项处理后的结果，不写入文件
+//
+// ctx 为 nil 时使用 opts.Context。opts 中的输出选项（Format、Formats、FormatOptions、OutputPath）不起作用。
+// 设置 opts.Chunk.Duration 且音频足够长时分段并行识别，见 ChunkOptions；设置 opts.Trim 时上传前去除长静音，见 TrimOptions。
+func Transcribe(ctx context.Context, inputFile string, opts ConvertOptions) (*types.ASRResult, error) {
+	if ctx != nil {
+		opts.Context = ctx
 	}
+	options := opts.withDefaults()
 
-	b.taskID = taskResp.TaskID
-	return taskResp.TaskID, nil
+	name := strings.TrimSuffix(filepath.Base(inputFile), filepath.Ext(inputFile))
+	return transcribeFile(inputFile, name, options, func(job *Job) (string, error) {
+		if err := job.SetData(inputFile); err != nil {
+			return "", err
+		}
+		if opts.JournalDir == "" {
+			return "", nil
+		}
+		return HashFile(inputFile)
+	})
 }
 
-func (b *BcutASR) QueryResult() (*types.ASRResult, error) {
-	req, err := http.NewRequestWithContext(b.ctx, http.MethodGet,
-		fmt.Sprintf("%s%s?model_id=7&task_id=%s",
-			types.GetAPIBaseURL(), types.APIQueryResult, b.taskID),
-		nil)
-	if err != nil {
-		return nil, fmt.Errorf("create request failed: %w", err)
+// TranscribeReader 识别从 r 读取的音视频，name 和 format 的含义见 Job.SetDataFromReader
+//
+// 设置了 JournalDir 时以加载后音频的哈希作为任务日志的 key，无法计算哈希的音频不记录任务日志。
+// 设置了 Chunk.Duration 或 Trim 时先将 r 写入临时文件，以便检测静音和按时间范围提取。
+func TranscribeReader(ctx context.Context, r io.Reader, name, format string, opts ConvertOptions) (*types.ASRResult, error) {
+	load := func(r io.Reader) func(*Job) (string, error) {
+		return func(job *Job) (string, error) {
+			if err := job.SetDataFromReader(r, name, format); err != nil {
+				return "", err
+			}
+			if opts.JournalDir == "" {
+				return "", nil
+			}
+			return audioKey(job), nil
+		}
+	}
+	if opts.Chunk.Duration <= 0 && opts.Trim == nil {
+		return transcribe(ctx, opts, load(r))
+	}
+
+	if ctx != nil {
+		opts.Context = ctx
 	}
+	options := opts.withDefaults()
 
-	resp, err := b.client.Do(req)
+	tmp, _, err := spoolTempFile(r, "tmp")
 	if err != nil {
 		return nil, err
 	}
-	defer resp.Body.Close()
-
-	var result types.ASRResponse
-	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+	defer tmp.Close()
+	if _, err := tmp.Seek(0, io.SeekStart); err != nil {
 		return nil, err
 	}
+	return transcribeFile(tmp.Name(), strings.TrimSuffix(name, filepath.Ext(name)), options, load(tmp.File))
+}
 
-	if result.Code != 0 {
-		return nil, fmt.Errorf("API error: %d - %s", result.Code, result.Message)
+// transcribeFile 识别文件 path，name 为不含扩展名的上传文件名
+//
+// 按 options 去除长静音、分段识别；两者都不需要时使用 load 加载整个文件。
+func transcribeFile(path, name string, options ConvertOptions, load func(*Job) (string, error)) (*types.ASRResult, error) {
+	var kept []types.TimeRange
+	if options.Trim != nil {
+		kept = planTrim(options.Context, options.Client.transcoder, path, options.Trim.withDefaults(), options.Progress)
+	}
+	if options.Chunk.Duration > 0 {
+		if spans := planFileChunks(path, options); len(spans) > 1 {
+			return transcribeChunks(path, name, spans, kept, options)
+		}
 	}
 
-	var taskResult types.TaskResultResponse
-	taskResultData, err := json.Marshal(result.Data)
-	if err := json.Unmarshal(taskResultData, &taskResult); err != nil {
-		return nil, err
+	if kept != nil {
+		load = func(job *Job) (string, error) {
+			if err := job.setSegments(path, name, kept); err != nil {
+				return "", err
+			}
+			if options.JournalDir == "" {
+				return "", nil
+			}
+			return audioKey(job), nil
+		}
 	}
+	return transcribe(options.Context, options, load)
+}
 
-	switch taskResult.State {
-	case types.StateStop: // 0 - 排队中
-		b.reportProgress(types.StageProcess, 50, "排队中...")
-	case types.StateRunning: // 1 - 处理中
-		b.reportProgress(types.StageProcess, 75, "正在识别...")
-	case types.StateError: // 3 - 失败
-		return nil, fmt.Errorf("task failed: %s", taskResult.Remark)
-	case types.StateComplete: // 4 - 完成
-		b.reportProgress(types.StageComplete, 100, "识别完成")
+// transcribe 识别 load 加载的音频，load 返回任务日志的 key
+func transcribe(ctx context.Context, opts ConvertOptions, load func(*Job) (string, error)) (*types.ASRResult, error) {
+	if ctx != nil {
+		opts.Context = ctx
 	}
+	options := opts.withDefaults()
 
-	if taskResult.State != types.StateComplete {
-		return nil, nil
-	}
+	job := options.Client.NewJob(options.Context).WithProgress(options.Progress)
+	defer job.Close()
 
-	var asrResult types.ASRResult
-	if err := json.Unmarshal([]byte(taskResult.Result), &asrResult); err != nil {
+	// 加载音频
+	journalKey, err := load(job)
+	if err != nil {
 		return nil, err
 	}
 
-	return &asrResult, nil
-}
+	// 任务日志，中断后从上次完成的步骤继续；无法计算 key 时不记录
+	var journal *Journal
+	if options.JournalDir != "" && journalKey != "" {
+		if journal, err = NewJournal(options.JournalDir); err != nil {
+			return nil, err
+		}
+		job.WithJournal(journal, journalKey)
+	}
 
-func (b *BcutASR) WithProgress(callback types.ProgressCallback) *BcutASR {
-	b.onProgress = callback
-	return b
-}
+	result, err := recognizeCached(job, options)
+	if err != nil {
+		return nil, err
+	}
+	result = job.TimeMap().Apply(result)
 
-func (b *BcutASR) reportProgress(stage types.ProgressStage, current int, description string) {
-	if b.onProgress != nil {
-		b.onProgress(types.ProgressInfo{
-			Stage:       stage,
-			Total:       100,
-			Current:     current,
-			Description: description,
-		})
+	// 识别完成，删除任务日志
+	if journal != nil {
+		if err := journal.Remove(journalKey); err != nil {
+			return nil, err
+		}
 	}
-}
 
-// ConvertOptions 转换选项
-type ConvertOptions struct {
-	Format     string                 // 输出格式，默认 "srt"
-	Interval   float64                // 轮询间隔（秒），默认 30.0
-	Progress   types.ProgressCallback // 进度回调，可选
-	OutputPath string                 // 输出路径，可选，默认与输入文件同目录
-	Context    context.Context        // 上下文，可选，用于取消操作
+	// 重新断句
+	if seg := options.segmentOptions(); !seg.IsZero() {
+		result = result.Resegment(seg)
+	}
+	return result, nil
 }
 
-// DefaultConvertOptions 默认转换选项
-var DefaultConvertOptions = ConvertOptions{
-	Format:   "srt",
-	Interval: 30.0,
+// TranscribeTo 识别音视频文件，将 opts.Format 格式的结果写入 w，并返回识别结果
+func TranscribeTo(ctx context.Context, w io.Writer, inputFile string, opts ConvertOptions) (*types.ASRResult, error) {
+	options := opts.withDefaults()
+	// 识别前检查格式，避免识别完成后才发现无法输出
+	if _, ok := types.LookupFormatter(options.Format); !ok {
+		return nil, fmt.Errorf("不支持的输出格式: %s", options.Format)
+	}
+
+	result, err := Transcribe(ctx, inputFile, options)
+	if err != nil {
+		return nil, err
+	}
+	if err := WriteResult(w, result, options.Format, options.FormatOptions); err != nil {
+		return result, err
+	}
+	return result, nil
 }
 
-// ConvertToSubtitle 快捷转换方法
+// ConvertToSubtitle 快捷转换方法：识别音视频文件并按 Format 或 Formats 写入字幕文件
 func ConvertToSubtitle(inputFile string, opts ...ConvertOptions) error {
 	// 使用默认选项
 	options := DefaultConvertOptions
@@ -412,35 +723,57 @@ func ConvertToSubtitle(inputFile string, opts ...ConvertOptions) error {
 	if len(opts) > 0 {
 		options = opts[0]
 	}
-	// 确保格式有值
-	if options.Format == "" {
-		options.Format = "srt"
+	options = options.withDefaults()
+
+	// 识别前检查格式，避免识别完成后才发现无法输出
+	formats := options.formats()
+	if len(formats) == 0 {
+		return errors.New("未指定输出格式")
 	}
-	// 确保间隔有值
-	if options.Interval <= 0 {
-		options.Interval = 30.0
+	for _, format := range formats {
+		if _, ok := types.LookupFormatter(format); !ok {
+			return fmt.Errorf("不支持的输出格式: %s", format)
+		}
 	}
-	// 确保上下文有值
-	if options.Context == nil {
-		options.Context = context.Background()
+
+	result, err := Transcribe(options.Context, inputFile, options)
+	if err != nil {
+		return err
 	}
 
-	bcutASR := New(options.Context).WithProgress(options.Progress)
+	// 根据格式输出结果，所有格式共用一次识别结果
+	_, err = WriteResultFiles(result, inputFile, options.OutputPath, formats, options.FormatOptions)
+	return err
+}
+
+// WriteResultFiles 将识别结果按每种格式写入文件，文件路径见 OutputPaths，返回写入的文件路径
+func WriteResultFiles(result *types.ASRResult, inputFile, outputPath string, formats []string, opts types.FormatOptions) ([]string, error) {
+	if outputPath != "" {
+		if err := os.MkdirAll(filepath.Dir(outputPath), 0755); err != nil {
+			return nil, fmt.Errorf("创建输出目录失败: %w", err)
+		}
+	}
 
-	// 设置输入文件
-	if err := bcutASR.SetData(inputFile); err != nil {
-		return err
+	paths := OutputPaths(inputFile, outputPath, formats)
+	for i, path := range paths {
+		if err := WriteResultFile(path, result, formats[i], opts); err != nil {
+			return nil, err
+		}
 	}
+	return paths, nil
+}
 
+// recognize 上传音频、创建任务并等待识别完成
+func recognize(job *Job, options ConvertOptions) (*types.ASRResult, error) {
 	// 上传文件
-	if err := bcutASR.Upload(); err != nil {
-		return err
+	if err := job.Upload(); err != nil {
+		return nil, err
 	}
 
 	// 创建任务
-	taskID, err := bcutASR.CreateTask()
+	taskID, err := job.CreateTask()
 	if err != nil {
-		return err
+		return nil, err
 	}
 	if options.Progress != nil {
 		options.Progress(types.ProgressInfo{
@@ -452,66 +785,94 @@ func ConvertToSubtitle(inputFile string, opts ...ConvertOptions) error {
 	}
 
 	// 轮询检查任务状态
-	ticker := time.NewTicker(time.Duration(options.Interval * float64(time.Second)))
-	defer ticker.Stop()
-
-	for {
-		select {
-		case <-options.Context.Done():
-			return options.Context.Err()
-		case <-ticker.C:
-			result, err := bcutASR.QueryResult()
-			if err != nil {
-				return err
-			}
+	return job.Wait(time.Duration(options.PollInterval * float64(time.Second)))
+}
 
-			if result == nil {
-				continue
-			}
+// recognizeCached 查询结果缓存，命中时跳过上传和识别，否则识别后写入缓存
+func recognizeCached(job *Job, options ConvertOptions) (*types.ASRResult, error) {
+	if options.Cache == nil {
+		return recognize(job, options)
+	}
+	cacheKey := audioKey(job)
+	if cacheKey == "" {
+		return recognize(job, options)
+	}
+	if result := loadCachedResult(options.Cache, cacheKey); result != nil {
+		job.reportProgress(types.StageComplete, 100, "命中识别结果缓存")
+		return result, nil
+	}
 
-			// 生成输出文件名
-			var outputFile string
-			if options.OutputPath != "" {
-				// 如果指定了输出路径
-				if err := os.MkdirAll(filepath.Dir(options.OutputPath), 0755); err != nil {
-					return fmt.Errorf("创建输出目录失败: %w", err)
-				}
-				// 如果指定的是目录，则在该目录下生成默认文件名
-				if info, err := os.Stat(options.OutputPath); err == nil && info.IsDir() {
-					outputFile = filepath.Join(options.OutputPath,
-						filepath.Base(inputFile[:len(inputFile)-len(filepath.Ext(inputFile))])+"."+options.Format)
-				} else {
-					// 否则使用指定的完整路径
-					outputFile = options.OutputPath
-				}
-			} else {
-				// 默认与输入文件同目录
-				outputFile = filepath.Join(
-					filepath.Dir(inputFile),
-					filepath.Base(inputFile[:len(inputFile)-len(filepath.Ext(inputFile))])+"."+options.Format,
-				)
-			}
+	result, err := recognize(job, options)
+	if err != nil {
+		return nil, err
+	}
+	storeCachedResult(options.Cache, cacheKey, result)
+	return result, nil
+}
 
-			// 根据格式输出结果
-			var output string
-			switch options.Format {
-			case "srt":
-				output = result.ToSRT()
-			case "lrc":
-				output = result.ToLRC()
-			case "txt":
-				output = result.ToTXT()
-			case "json":
-				jsonBytes, err := json.MarshalIndent(result, "", "  ")
-				if err != nil {
-					return fmt.Errorf("JSON序列化失败: %w", err)
-				}
-				output = string(jsonBytes)
-			default:
-				return fmt.Errorf("不支持的输出格式: %s", options.Format)
-			}
+// audioKey 返回当前音频的缓存 key，无法计算时（如只能顺序读取的音频）返回空，此时不使用缓存和任务日志
+func audioKey(job *Job) string {
+	key, err := job.CacheKey()
+	if err != nil {
+		return ""
+	}
+	return key
+}
 
-			return os.WriteFile(outputFile, []byte(output), 0644)
-		}
+// loadCachedResult 读取缓存的识别结果，缓存不可用或内容无效时视为未命中
+func loadCachedResult(cache ResultCache, key string) *types.ASRResult {
+	data, ok, err := cache.Get(key)
+	if err != nil || !ok {
+		return nil
+	}
+	var result types.ASRResult
+	if err := json.Unmarshal(data, &result); err != nil {
+		return nil
+	}
+	return &result
+}
+
+// storeCachedResult 缓存识别结果，缓存只用于加速，写入失败不影响转换
+func storeCachedResult(cache ResultCache, key string, result *types.ASRResult) {
+	data, err := json.Marshal(result)
+	if err != nil {
+		return
 	}
+	_ = cache.Put(key, data)
+}
+
+// FormatResult 将识别结果转换为指定格式
+func FormatResult(result *types.ASRResult, format string, opts types.FormatOptions) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := WriteResult(&buf, result, format, opts); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// WriteResult 将识别结果按指定格式写入 w，不在内存中保存完整输出
+func WriteResult(w io.Writer, result *types.ASRResult, format string, opts types.FormatOptions) error {
+	formatter, ok := types.LookupFormatter(format)
+	if !ok {
+		return fmt.Errorf("不支持的输出格式: %s", format)
+	}
+	return formatter.Write(w, result, opts)
+}
+
+// WriteResultFile 将识别结果按指定格式写入文件，失败时删除写了一半的文件
+func WriteResultFile(path string, result *types.ASRResult, format string, opts types.FormatOptions) error {
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err := WriteResult(file, result, format, opts); err != nil {
+		file.Close()
+		os.Remove(path)
+		return err
+	}
+	if err := file.Close(); err != nil {
+		os.Remove(path)
+		return err
+	}
+	return nil
 }
@@ -1,13 +1,21 @@
 package asr
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
 	"net/http"
 	"net/http/httptest"
 	"os"
 	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
 	"testing"
+	"testing/iotest"
 
 	"github.com/562589540/bcut-asr-go/pkg/types"
 )
@@ -15,11 +23,12 @@ import (
 func TestBcutASR_SetData(t *testing.T) {
 	// 创建临时测试文件
 	tmpFile := filepath.Join(t.TempDir(), "test.mp3")
-	if err := os.WriteFile(tmpFile, []byte("test data"), 0644); err != nil {
+	if err := os.WriteFile(tmpFile, mp3Data, 0644); err != nil {
 		t.Fatal(err)
 	}
 
 	asr := New(context.Background(), "")
+	defer asr.Close()
 	if err := asr.SetData(tmpFile); err != nil {
 		t.Errorf("SetData() error = %v", err)
 	}
@@ -35,7 +44,8 @@ func TestBcutASR_SetData(t *testing.T) {
 
 func TestBcutASR_Upload(t *testing.T) {
 	// 模拟服务器
-	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+	var server *httptest.Server
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		switch r.URL.Path {
 		case "/resource/create":
 			// 模拟申请上传响应
@@ -43,13 +53,16 @@ func TestBcutASR_Upload(t *testing.T) {
 				Code: 0,
 				Data: types.ResourceCreateResponse{
 					ResourceID: "test-resource",
-					UploadURLs: []string{"http://test.com/upload"},
+					UploadURLs: []string{server.URL + "/upload"},
 					UploadID:   "test-upload",
 					InBossKey:  "test-key",
 					PerSize:    1024,
 				},
 			}
 			json.NewEncoder(w).Encode(resp)
+		case "/upload":
+			// 模拟分片上传响应
+			w.Header().Set("Etag", "test-etag")
 		case "/resource/create/complete":
 			// 模拟提交上传响应
 			resp := types.ASRResponse{
@@ -64,15 +77,8 @@ func TestBcutASR_Upload(t *testing.T) {
 	}))
 	defer server.Close()
 
-	// 修改API基础URL为测试服务器
-	origBaseURL := types.GetAPIBaseURL()
-	types.SetAPIBaseURL(server.URL)
-	defer func() { types.SetAPIBaseURL(origBaseURL) }()
-
-	asr := New(context.Background(), "")
-	asr.soundName = "test.mp3"
-	asr.soundData = []byte("test data")
-	asr.soundFormat = "mp3"
+	asr := NewClient(WithBaseURL(server.URL)).NewJob(context.Background())
+	asr.SetReaderAt(bytes.NewReader(mp3Data), int64(len(mp3Data)), "test.mp3", "mp3")
 
 	if err := asr.Upload(); err != nil {
 		t.Errorf("Upload() error = %v", err)
@@ -161,3 +167,304 @@ func TestBcutASR_QueryResult(t *testing.T) {
 		t.Errorf("transcript = %v, want %v", result.Utterances[0].Transcript, "test transcript")
 	}
 }
+
+// newFakeServer 模拟完整的上传、任务和查询流程，记录每次提交的 etags
+func newFakeServer(t *testing.T, commits chan<- string) *httptest.Server {
+	return newFakeServerWithHook(t, commits, nil)
+}
+
+// newFakeServerWithHook 同 newFakeServer，hook 返回 true 时表示请求已处理
+func newFakeServerWithHook(t *testing.T, commits chan<- string, hook func(w http.ResponseWriter, r *http.Request) bool) *httptest.Server {
+	t.Helper()
+	var server *httptest.Server
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if hook != nil && hook(w, r) {
+			return
+		}
+		switch r.URL.Path {
+		case "/resource/create":
+			r.ParseForm()
+			size, _ := strconv.Atoi(r.Form.Get("size"))
+			name := r.Form.Get("name")
+			var urls []string
+			for i := 0; i*4 < size; i++ {
+				urls = append(urls, fmt.Sprintf("%s/upload/%s/%d", server.URL, name, i))
+			}
+			json.NewEncoder(w).Encode(types.ASRResponse{
+				Code: 0,
+				Data: types.ResourceCreateResponse{
+					ResourceID: name,
+					UploadURLs: urls,
+					UploadID:   name,
+					PerSize:    4,
+				},
+			})
+		case "/resource/create/complete":
+			r.ParseForm()
+			if commits != nil {
+				commits <- r.Form.Get("etags")
+			}
+			json.NewEncoder(w).Encode(types.ASRResponse{
+				Code: 0,
+				Data: types.ResourceCompleteResponse{
+					DownloadURL: "http://test.com/" + r.Form.Get("resource_id"),
+				},
+			})
+		case "/task":
+			var body map[string]string
+			json.NewDecoder(r.Body).Decode(&body)
+			json.NewEncoder(w).Encode(types.ASRResponse{
+				Code: 0,
+				Data: types.TaskCreateResponse{TaskID: strings.TrimPrefix(body["resource"], "http://test.com/")},
+			})
+		case "/task/result":
+			json.NewEncoder(w).Encode(types.ASRResponse{
+				Code: 0,
+				Data: types.TaskResultResponse{
+					State:  types.StateComplete,
+					Result: fmt.Sprintf(`{"utterances":[{"transcript":%q}]}`, r.URL.Query().Get("task_id")),
+				},
+			})
+		default:
+			if strings.HasPrefix(r.URL.Path, "/upload/") {
+				data, _ := io.ReadAll(r.Body)
+				w.Header().Set("Etag", string(data))
+			}
+		}
+	}))
+	return server
+}
+
+func TestJob_UploadTwice(t *testing.T) {
+	commits := make(chan string, 2)
+	server := newFakeServer(t, commits)
+	defer server.Close()
+
+	job := NewClient(WithBaseURL(server.URL)).NewJob(context.Background())
+
+	job.SetReaderAt(strings.NewReader("aaaabbbb"), 8, "a.mp3", "mp3")
+	if err := job.Upload(); err != nil {
+		t.Fatalf("Upload() error = %v", err)
+	}
+	job.SetReaderAt(strings.NewReader("cccc"), 4, "b.mp3", "mp3")
+	if err := job.Upload(); err != nil {
+		t.Fatalf("Upload() error = %v", err)
+	}
+
+	for _, want := range []string{"aaaa,bbbb", "cccc"} {
+		if got := <-commits; got != want {
+			t.Errorf("etags = %q, want %q", got, want)
+		}
+	}
+}
+
+func TestClient_ConcurrentJobs(t *testing.T) {
+	server := newFakeServer(t, nil)
+	defer server.Close()
+
+	client := NewClient(WithBaseURL(server.URL))
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+
+			name := fmt.Sprintf("file%d.mp3", i)
+			job := client.NewJob(context.Background())
+			job.SetReaderAt(strings.NewReader(strings.Repeat("x", i+1)), int64(i+1), name, "mp3")
+			if err := job.Upload(); err != nil {
+				t.Errorf("Upload() error = %v", err)
+				return
+			}
+			if _, err := job.CreateTask(); err != nil {
+				t.Errorf("CreateTask() error = %v", err)
+				return
+			}
+			result, err := job.QueryResult()
+			if err != nil {
+				t.Errorf("QueryResult() error = %v", err)
+				return
+			}
+			if got := result.Utterances[0].Transcript; got != name {
+				t.Errorf("transcript = %v, want %v", got, name)
+			}
+		}(i)
+	}
+	wg.Wait()
+}
+
+func TestJob_UploadStream(t *testing.T) {
+	commits := make(chan string, 1)
+	server := newFakeServer(t, commits)
+	defer server.Close()
+
+	job := NewClient(WithBaseURL(server.URL)).NewJob(context.Background())
+	job.SetReader(iotest.OneByteReader(strings.NewReader("aaaabbbbcc")), 10, "a.mp3", "mp3")
+	if err := job.Upload(); err != nil {
+		t.Fatalf("Upload() error = %v", err)
+	}
+	if got, want := <-commits, "aaaa,bbbb,cc"; got != want {
+		t.Errorf("etags = %q, want %q", got, want)
+	}
+
+	job.SetReader(strings.NewReader("short"), 10, "b.mp3", "mp3")
+	if err := job.Upload(); err == nil {
+		t.Error("Upload() with short reader should fail")
+	}
+}
+
+func TestFormatResult(t *testing.T) {
+	types.RegisterFormatter(types.FormatterFunc{
+		FormatName: "test-count",
+		WriteFunc: func(w io.Writer, r *types.ASRResult, _ types.FormatOptions) error {
+			_, err := fmt.Fprintf(w, "%d", len(r.Utterances))
+			return err
+		},
+	})
+	t.Cleanup(func() { types.UnregisterFormatter("test-count") })
+
+	result := &types.ASRResult{Utterances: []types.Utterance{{Transcript: "a"}, {Transcript: "b"}}}
+	output, err := FormatResult(result, "test-count", types.FormatOptions{})
+	if err != nil {
+		t.Fatalf("FormatResult() error = %v", err)
+	}
+	if string(output) != "2" {
+		t.Errorf("FormatResult() = %q, want %q", output, "2")
+	}
+
+	if _, err := FormatResult(result, "unknown", types.FormatOptions{}); err == nil {
+		t.Error("FormatResult() 不支持的格式应返回错误")
+	}
+
+	// 不支持的格式在识别之前返回错误
+	err = ConvertToSubtitle(filepath.Join(t.TempDir(), "missing.mp3"), ConvertOptions{Format: "unknown"})
+	if err == nil || !strings.Contains(err.Error(), "不支持的输出格式") {
+		t.Errorf("ConvertToSubtitle() error = %v, want 不支持的输出格式", err)
+	}
+}
+
+func TestConvertToSubtitle_MultipleFormats(t *testing.T) {
+	commits := make(chan string, 4)
+	server := newFakeServer(t, commits)
+	defer server.Close()
+
+	dir := t.TempDir()
+	input := filepath.Join(dir, "test.mp3")
+	if err := os.WriteFile(input, []byte("ID3test"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	err := ConvertToSubtitle(input, ConvertOptions{
+		Formats:      []string{"srt", "VTT", "json", "srt", "ebuttd"},
+		PollInterval: 0.001,
+		OutputPath:   filepath.Join(dir, "out", "sub.srt"),
+		Client:       NewClient(WithBaseURL(server.URL)),
+	})
+	if err != nil {
+		t.Fatalf("ConvertToSubtitle() error = %v", err)
+	}
+	if len(commits) != 1 {
+		t.Errorf("上传了 %d 次, want 1", len(commits))
+	}
+	for _, name := range []string{"sub.srt", "sub.vtt", "sub.json", "sub.xml"} {
+		if _, err := os.Stat(filepath.Join(dir, "out", name)); err != nil {
+			t.Errorf("%s 未输出: %v", name, err)
+		}
+	}
+}
+
+func TestOutputPaths(t *testing.T) {
+	dir := t.TempDir()
+	input := filepath.Join("media", "video.mp4")
+
+	tests := []struct {
+		name       string
+		outputPath string
+		formats    []string
+		want       []string
+	}{
+		{"默认", "", []string{"srt", "ebuttd"}, []string{filepath.Join("media", "video.srt"), filepath.Join("media", "video.xml")}},
+		{"目录", dir, []string{"vtt"}, []string{filepath.Join(dir, "video.vtt")}},
+		{"单个文件", "out/a.txt", []string{"srt"}, []string{"out/a.txt"}},
+		{"多个文件", filepath.Join("out", "a.srt"), []string{"srt", "json"}, []string{filepath.Join("out", "a.srt"), filepath.Join("out", "a.json")}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := OutputPaths(input, tt.outputPath, tt.formats)
+			if strings.Join(got, "|") != strings.Join(tt.want, "|") {
+				t.Errorf("OutputPaths() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTranscribe(t *testing.T) {
+	server := newFakeServer(t, nil)
+	defer server.Close()
+
+	dir := t.TempDir()
+	input := filepath.Join(dir, "test.mp3")
+	if err := os.WriteFile(input, []byte("ID3test"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	options := ConvertOptions{
+		PollInterval: 0.001,
+		Client:       NewClient(WithBaseURL(server.URL)),
+	}
+
+	result, err := Transcribe(context.Background(), input, options)
+	if err != nil {
+		t.Fatalf("Transcribe() error = %v", err)
+	}
+	if len(result.Utterances) == 0 {
+		t.Fatal("Transcribe() 返回空结果")
+	}
+
+	var buf strings.Builder
+	options.Format = "txt"
+	got, err := TranscribeTo(context.Background(), &buf, input, options)
+	if err != nil {
+		t.Fatalf("TranscribeTo() error = %v", err)
+	}
+	if buf.String() != got.ToTXT() {
+		t.Errorf("TranscribeTo() 写入 %q, want %q", buf.String(), got.ToTXT())
+	}
+
+	entries, _ := os.ReadDir(dir)
+	if len(entries) != 1 {
+		t.Errorf("Transcribe 不应写入文件, 目录中有 %d 个文件", len(entries))
+	}
+
+	options.Format = "unknown"
+	if _, err := TranscribeTo(context.Background(), &buf, input, options); err == nil {
+		t.Error("TranscribeTo() 不支持的格式应返回错误")
+	}
+}
+
+func TestTranscribeReader(t *testing.T) {
+	commits := make(chan string, 1)
+	server := newFakeServer(t, commits)
+	defer server.Close()
+
+	options := ConvertOptions{
+		PollInterval: 0.001,
+		Client:       NewClient(WithBaseURL(server.URL)),
+		JournalDir:   t.TempDir(),
+	}
+	result, err := TranscribeReader(context.Background(), strings.NewReader("ID3stdin-data"), "stdin", "MP3", options)
+	if err != nil {
+		t.Fatalf("TranscribeReader() error = %v", err)
+	}
+	if len(result.Utterances) == 0 {
+		t.Fatal("TranscribeReader() 返回空结果")
+	}
+	if got := <-commits; got != "ID3s,tdin,-dat,a" {
+		t.Errorf("上传的内容 = %q", got)
+	}
+
+	job := NewClient(WithBaseURL(server.URL)).NewJob(context.Background())
+	if err := job.SetDataFromReader(strings.NewReader(""), "stdin", "mp3"); !errors.Is(err, ErrNoAudio) {
+		t.Errorf("SetDataFromReader() 空输入 error = %v, want %v", err, ErrNoAudio)
+	}
+}
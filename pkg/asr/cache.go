@@ -0,0 +1,168 @@
+package asr
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ResultCache 识别结果缓存，保存服务端返回的原始 types.ASRResult JSON
+type ResultCache interface {
+	// Get 读取缓存，未命中时返回 nil, false, nil
+	Get(key string) ([]byte, bool, error)
+	// Put 写入缓存
+	Put(key string, data []byte) error
+}
+
+// DirCache 以目录保存的结果缓存，每个 key 对应一个文件
+//
+// 文件的修改时间即最近使用时间：超过 maxAge 的缓存视为过期，
+// 总大小超过 maxSize 时从最久未使用的缓存开始删除。
+type DirCache struct {
+	dir     string
+	maxSize int64
+	maxAge  time.Duration
+	mu      sync.Mutex
+}
+
+// NewDirCache 创建目录缓存，maxSize（字节）和 maxAge 为 0 时不限制
+func NewDirCache(dir string, maxSize int64, maxAge time.Duration) (*DirCache, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, fmt.Errorf("创建缓存目录失败: %w", err)
+	}
+	return &DirCache{dir: dir, maxSize: maxSize, maxAge: maxAge}, nil
+}
+
+func (c *DirCache) path(key string) string {
+	return filepath.Join(c.dir, key+".json")
+}
+
+// Get 读取缓存，命中时更新最近使用时间
+func (c *DirCache) Get(key string) ([]byte, bool, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	path := c.path(key)
+	info, err := os.Stat(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, false, err
+	}
+	if c.maxAge > 0 && time.Since(info.ModTime()) > c.maxAge {
+		os.Remove(path)
+		return nil, false, nil
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, false, err
+	}
+	now := time.Now()
+	os.Chtimes(path, now, now)
+	return data, true, nil
+}
+
+// Put 写入缓存并清理过期和超出大小的缓存
+func (c *DirCache) Put(key string, data []byte) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	tmp, err := os.CreateTemp(c.dir, key+".*.tmp")
+	if err != nil {
+		return err
+	}
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := os.Rename(tmp.Name(), c.path(key)); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	return c.evict()
+}
+
+// evict 删除过期的缓存，总大小超出限制时删除最久未使用的缓存
+func (c *DirCache) evict() error {
+	if c.maxSize <= 0 && c.maxAge <= 0 {
+		return nil
+	}
+
+	entries, err := os.ReadDir(c.dir)
+	if err != nil {
+		return err
+	}
+
+	type cacheFile struct {
+		path    string
+		size    int64
+		modTime time.Time
+	}
+	var (
+		files []cacheFile
+		total int64
+	)
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.HasSuffix(entry.Name(), ".json") {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil {
+			continue
+		}
+		path := filepath.Join(c.dir, entry.Name())
+		if c.maxAge > 0 && time.Since(info.ModTime()) > c.maxAge {
+			os.Remove(path)
+			continue
+		}
+		files = append(files, cacheFile{path: path, size: info.Size(), modTime: info.ModTime()})
+		total += info.Size()
+	}
+
+	if c.maxSize <= 0 || total <= c.maxSize {
+		return nil
+	}
+	sort.Slice(files, func(a, b int) bool {
+		return files[a].modTime.Before(files[b].modTime)
+	})
+	for _, f := range files {
+		if total <= c.maxSize {
+			break
+		}
+		if err := os.Remove(f.path); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return err
+		}
+		total -= f.size
+	}
+	return nil
+}
+
+// CacheKey 返回当前音频的缓存 key：音频内容的 SHA-256 加模型ID
+//
+// 只能对 SetData 或 SetReaderAt 设置的音频计算，顺序读取的音频会返回错误。
+func (j *Job) CacheKey() (string, error) {
+	if j.source == nil {
+		return "", errors.New("顺序读取的音频无法计算缓存key")
+	}
+
+	h := sha256.New()
+	if _, err := io.Copy(h, io.NewSectionReader(j.source, 0, j.soundSize)); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)) + "-" + j.client.modelID, nil
+}
@@ -0,0 +1,131 @@
+package asr
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestDirCache(t *testing.T) {
+	dir := t.TempDir()
+	cache, err := NewDirCache(dir, 10, time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, ok, err := cache.Get("a"); ok || err != nil {
+		t.Errorf("Get() on empty cache = %v, %v", ok, err)
+	}
+
+	if err := cache.Put("a", []byte("aaaa")); err != nil {
+		t.Fatal(err)
+	}
+	if data, ok, err := cache.Get("a"); !ok || err != nil || string(data) != "aaaa" {
+		t.Errorf("Get() = %q, %v, %v", data, ok, err)
+	}
+
+	// 超过总大小时删除最久未使用的缓存
+	old := time.Now().Add(-time.Minute)
+	os.Chtimes(filepath.Join(dir, "a.json"), old, old)
+	cache.Put("b", []byte("bbbb"))
+	cache.Get("a")
+	cache.Put("c", []byte("cccc"))
+	if _, ok, _ := cache.Get("b"); ok {
+		t.Error("least recently used entry b should be evicted")
+	}
+	for _, key := range []string{"a", "c"} {
+		if _, ok, _ := cache.Get(key); !ok {
+			t.Errorf("entry %s should be kept", key)
+		}
+	}
+
+	// 过期的缓存视为未命中
+	expired := time.Now().Add(-2 * time.Hour)
+	os.Chtimes(filepath.Join(dir, "c.json"), expired, expired)
+	if _, ok, _ := cache.Get("c"); ok {
+		t.Error("expired entry c should miss")
+	}
+	if _, err := os.Stat(filepath.Join(dir, "c.json")); !os.IsNotExist(err) {
+		t.Error("expired entry c should be removed")
+	}
+}
+
+func TestConvertToSubtitle_Cache(t *testing.T) {
+	var calls int32
+	server := newFakeServerWithHook(t, nil, func(w http.ResponseWriter, r *http.Request) bool {
+		atomic.AddInt32(&calls, 1)
+		return false
+	})
+	defer server.Close()
+
+	dir := t.TempDir()
+	input := filepath.Join(dir, "test.mp3")
+	if err := os.WriteFile(input, []byte("ID3test"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	cache, err := NewDirCache(filepath.Join(dir, "cache"), 0, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for i, format := range []string{"txt", "srt"} {
+		err := ConvertToSubtitle(input, ConvertOptions{
+			Format:       format,
+			PollInterval: 0.001,
+			Client:       NewClient(WithBaseURL(server.URL)),
+			Cache:        cache,
+		})
+		if err != nil {
+			t.Fatalf("ConvertToSubtitle() error = %v", err)
+		}
+		if _, err := os.Stat(filepath.Join(dir, "test."+format)); err != nil {
+			t.Errorf("output %s not written: %v", format, err)
+		}
+
+		got := atomic.LoadInt32(&calls)
+		if i == 0 && got == 0 {
+			t.Fatal("first run should call the service")
+		}
+		if i == 1 && got != 0 {
+			t.Errorf("second run called the service %d times, want 0", got)
+		}
+		atomic.StoreInt32(&calls, 0)
+	}
+}
+
+func TestTranscribeReader_NoCacheKey(t *testing.T) {
+	server := newFakeServer(t, nil)
+	defer server.Close()
+
+	dir := t.TempDir()
+	cache, err := NewDirCache(filepath.Join(dir, "cache"), 0, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// 转码输出大小已知但只能顺序读取，无法计算缓存 key
+	client := NewClient(WithBaseURL(server.URL), WithTranscoder(TranscoderFunc(func(ctx context.Context, in TranscodeInput) (*TranscodeOutput, error) {
+		return &TranscodeOutput{Audio: streamAudio{strings.NewReader("ID3audio")}, Size: 8, Format: "mp3"}, nil
+	})))
+
+	result, err := TranscribeReader(context.Background(), bytes.NewReader(mp4Data), "stdin", "", ConvertOptions{
+		PollInterval: 0.001,
+		Client:       client,
+		Cache:        cache,
+		JournalDir:   filepath.Join(dir, "journal"),
+	})
+	if err != nil {
+		t.Fatalf("TranscribeReader() error = %v", err)
+	}
+	if len(result.Utterances) != 1 || result.Utterances[0].Transcript != "stdin.mp3" {
+		t.Errorf("TranscribeReader() = %+v", result.Utterances)
+	}
+	if entries, _ := os.ReadDir(filepath.Join(dir, "cache")); len(entries) != 0 {
+		t.Errorf("无法计算 key 时不应写入缓存: %v", entries)
+	}
+}
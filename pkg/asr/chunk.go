@@ -0,0 +1,276 @@
+package asr
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/562589540/bcut-asr-go/pkg/types"
+)
+
+// ChunkOptions 长音频分段识别选项
+//
+// 设置 Duration 后，时长超过 Duration 的音频在目标切分点附近的静音处切分为多段，
+// 各段作为独立的任务并行上传和识别，结果合并到原音频的时间轴。
+// 分段需要客户端的 Transcoder 实现 ChunkTranscoder，否则按整个文件识别。
+type ChunkOptions struct {
+	Duration     time.Duration // 每段的目标时长，0 表示不分段
+	Concurrency  int           // 同时识别的段数，默认为 3
+	SearchWindow time.Duration // 在目标切分点前后该范围内寻找静音，默认为 Duration 的 1/10
+	MinSilence   time.Duration // 可以作为切分点的最短静音，默认为 0.5 秒
+	NoiseLevel   float64       // 音量低于该值（dB）视为静音，默认为 -35
+	Overlap      time.Duration // 找不到静音时相邻两段的重叠时长，默认为 2 秒，重叠部分的结果合并时去重
+}
+
+// withDefaults 填充未设置的选项
+func (o ChunkOptions) withDefaults() ChunkOptions {
+	if o.Concurrency <= 0 {
+		o.Concurrency = 3
+	}
+	if o.SearchWindow <= 0 || o.SearchWindow > o.Duration/2 {
+		o.SearchWindow = o.Duration / 10
+	}
+	if o.MinSilence <= 0 {
+		o.MinSilence = 500 * time.Millisecond
+	}
+	if o.NoiseLevel == 0 {
+		o.NoiseLevel = -35
+	}
+	if o.Overlap <= 0 {
+		o.Overlap = 2 * time.Second
+	}
+	if o.Overlap > o.Duration/4 {
+		o.Overlap = o.Duration / 4
+	}
+	return o
+}
+
+// ChunkTranscoder 支持分段识别的 Transcoder，FFmpegTranscoder 实现了该接口
+type ChunkTranscoder interface {
+	Transcoder
+	// Probe 读取媒体信息，分段需要其中的时长
+	Probe(ctx context.Context, path string) (*types.MediaInfo, error)
+	// DetectSilence 检测音量低于 noise（dB）且持续至少 minDuration 的静音
+	DetectSilence(ctx context.Context, path string, noise float64, minDuration time.Duration) ([]types.Silence, error)
+	// TranscodeRange 提取从 start 开始、时长为 duration 的音频
+	TranscodeRange(ctx context.Context, path string, start, duration time.Duration) (*TranscodeOutput, error)
+}
+
+// chunkSpan 分段在原音频中的时间范围
+type chunkSpan struct {
+	Start time.Duration
+	End   time.Duration
+}
+
+// planChunks 将时长为 total 的音频切分为约 opts.Duration 的分段
+//
+// 优先在目标切分点前后 SearchWindow 内离目标最近的静音中点切分；找不到静音时在目标点切分，
+// 相邻两段重叠 Overlap。剩余时长不超过 Duration + SearchWindow 时作为最后一段。
+func planChunks(total time.Duration, silences []types.Silence, opts ChunkOptions) []chunkSpan {
+	var spans []chunkSpan
+	start := time.Duration(0)
+	for total-start > opts.Duration+opts.SearchWindow {
+		target := start + opts.Duration
+		if cut, ok := findCut(silences, target, opts.SearchWindow, start); ok {
+			spans = append(spans, chunkSpan{Start: start, End: cut})
+			start = cut
+			continue
+		}
+		spans = append(spans, chunkSpan{Start: start, End: target + opts.Overlap/2})
+		start = target - opts.Overlap/2
+	}
+	return append(spans, chunkSpan{Start: start, End: total})
+}
+
+// findCut 返回中点在 [target-window, target+window] 内且在 after 之后、离 target 最近的静音中点
+func findCut(silences []types.Silence, target, window, after time.Duration) (time.Duration, bool) {
+	var (
+		best  time.Duration
+		found bool
+	)
+	for _, s := range silences {
+		mid := s.Start + (s.End-s.Start)/2
+		if mid <= after || mid < target-window || mid > target+window {
+			continue
+		}
+		if !found || absDuration(mid-target) < absDuration(best-target) {
+			best, found = mid, true
+		}
+	}
+	return best, found
+}
+
+func absDuration(d time.Duration) time.Duration {
+	if d < 0 {
+		return -d
+	}
+	return d
+}
+
+// planFileChunks 读取时长并检测静音，返回分段；不需要分段或无法分段时返回 nil
+func planFileChunks(path string, options ConvertOptions) []chunkSpan {
+	ct, ok := options.Client.transcoder.(ChunkTranscoder)
+	if !ok {
+		return nil
+	}
+	chunk := options.Chunk.withDefaults()
+
+	reportProgress(options.Progress, types.StageInit, 5, "读取媒体时长...")
+	info, err := ct.Probe(options.Context, path)
+	if err != nil || !info.HasAudio() || info.Duration <= chunk.Duration+chunk.SearchWindow {
+		return nil
+	}
+
+	reportProgress(options.Progress, types.StageInit, 10, "检测静音...")
+	// 检测失败时在目标位置切分
+	silences, _ := ct.DetectSilence(options.Context, path, chunk.NoiseLevel, chunk.MinSilence)
+	return planChunks(info.Duration, silences, chunk)
+}
+
+// transcribeChunks 并行识别 path 的各个分段并合并结果，name 为上传文件名的前缀
+//
+// kept 不为 nil 时每段只上传其中与 kept 重叠的部分，见 TrimOptions。
+func transcribeChunks(path, name string, spans []chunkSpan, kept []types.TimeRange, options ConvertOptions) (*types.ASRResult, error) {
+	ct := options.Client.transcoder.(ChunkTranscoder)
+	chunk := options.Chunk.withDefaults()
+
+	// 每段以文件哈希和时间范围作为任务日志的 key
+	var (
+		journal  *Journal
+		fileHash string
+		err      error
+	)
+	if options.JournalDir != "" {
+		if journal, err = NewJournal(options.JournalDir); err != nil {
+			return nil, err
+		}
+		if fileHash, err = HashFile(path); err != nil {
+			return nil, err
+		}
+	}
+
+	workers := chunk.Concurrency
+	if workers > len(spans) {
+		workers = len(spans)
+	}
+
+	ctx, cancel := context.WithCancel(options.Context)
+	defer cancel()
+
+	var (
+		parts    = make([]types.ResultPart, len(spans))
+		mu       sync.Mutex
+		done     int
+		wg       sync.WaitGroup
+		errOnce  sync.Once
+		firstErr error
+	)
+	fail := func(err error) {
+		errOnce.Do(func() {
+			firstErr = err
+			cancel()
+		})
+	}
+
+	reportProgress(options.Progress, types.StageProcess, 0, fmt.Sprintf("分为 %d 段识别", len(spans)))
+	indexes := make(chan int)
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range indexes {
+				span := spans[i]
+				var key string
+				if journal != nil {
+					key = fmt.Sprintf("%s-%d-%d", fileHash, span.Start.Milliseconds(), span.End.Milliseconds())
+				}
+				result, err := recognizeChunk(ctx, ct, path, fmt.Sprintf("%s_%03d", name, i+1), span, kept, journal, key, options)
+				if err != nil {
+					fail(fmt.Errorf("第 %d 段识别失败: %w", i+1, err))
+					continue
+				}
+
+				mu.Lock()
+				parts[i] = types.ResultPart{Result: result, Start: span.Start.Milliseconds(), End: span.End.Milliseconds()}
+				done++
+				reportProgress(options.Progress, types.StageProcess, done*100/len(spans), fmt.Sprintf("已完成 %d/%d 段", done, len(spans)))
+				mu.Unlock()
+			}
+		}()
+	}
+
+feed:
+	for i := range spans {
+		select {
+		case indexes <- i:
+		case <-ctx.Done():
+			break feed
+		}
+	}
+	close(indexes)
+	wg.Wait()
+
+	if firstErr != nil {
+		return nil, firstErr
+	}
+	if err := options.Context.Err(); err != nil {
+		return nil, err
+	}
+
+	result := types.MergeResults(parts)
+	reportProgress(options.Progress, types.StageComplete, 100, "识别完成")
+
+	// 重新断句
+	if seg := options.segmentOptions(); !seg.IsZero() {
+		result = result.Resegment(seg)
+	}
+	return result, nil
+}
+
+// recognizeChunk 提取并识别一个分段，kept 不为 nil 时去除其中的静音，key 不为空时记录任务日志
+func recognizeChunk(ctx context.Context, ct ChunkTranscoder, path, name string, span chunkSpan, kept []types.TimeRange, journal *Journal, key string, options ConvertOptions) (*types.ASRResult, error) {
+	job := options.Client.NewJob(ctx)
+	defer job.Close()
+
+	if kept != nil {
+		segments := clipRanges(kept, span)
+		if len(segments) == 0 {
+			// 整段都是静音
+			return &types.ASRResult{}, nil
+		}
+		if err := job.setSegments(path, name, segments); err != nil {
+			return nil, err
+		}
+		// 结果的时间相对于分段开始，合并时再平移到原音频
+		for i := range segments {
+			segments[i].Start -= span.Start
+			segments[i].End -= span.Start
+		}
+		job.timeMap = types.NewTimeMap(segments)
+	} else {
+		out, err := ct.TranscodeRange(ctx, path, span.Start, span.End-span.Start)
+		if err != nil {
+			return nil, err
+		}
+		if err := job.setTranscoded(out, name); err != nil {
+			return nil, err
+		}
+	}
+	if journal != nil {
+		job.WithJournal(journal, key)
+	}
+
+	// 各段并行识别，进度由 transcribeChunks 按完成的段数汇总
+	options.Progress = nil
+	result, err := recognizeCached(job, options)
+	if err != nil {
+		return nil, err
+	}
+	if journal != nil {
+		if err := journal.Remove(key); err != nil {
+			return nil, err
+		}
+	}
+	return job.TimeMap().Apply(result), nil
+}
@@ -0,0 +1,224 @@
+package asr
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/562589540/bcut-asr-go/pkg/types"
+)
+
+func TestPlanChunks(t *testing.T) {
+	opts := ChunkOptions{Duration: 30 * time.Minute}.withDefaults()
+	silences := []types.Silence{
+		// 离目标切分点太远
+		{Start: 20 * time.Minute, End: 20*time.Minute + 2*time.Second},
+		{Start: 28 * time.Minute, End: 28*time.Minute + 2*time.Second},
+		// 离 30 分钟最近
+		{Start: 31 * time.Minute, End: 31*time.Minute + 2*time.Second},
+	}
+
+	got := planChunks(70*time.Minute, silences, opts)
+	want := []chunkSpan{
+		{Start: 0, End: 31*time.Minute + time.Second},
+		// 61 分钟附近没有静音，在目标位置切分并重叠 2 秒
+		{Start: 31*time.Minute + time.Second, End: 61*time.Minute + 2*time.Second},
+		{Start: 61 * time.Minute, End: 70 * time.Minute},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("planChunks() = %v, want %v", got, want)
+	}
+
+	// 不超过 Duration + SearchWindow 时不分段
+	if got := planChunks(32*time.Minute, nil, opts); len(got) != 1 {
+		t.Errorf("planChunks() = %v, want 1 段", got)
+	}
+}
+
+func TestChunkOptions_WithDefaults(t *testing.T) {
+	got := ChunkOptions{Duration: 4 * time.Second, SearchWindow: time.Minute, Overlap: 10 * time.Second}.withDefaults()
+	want := ChunkOptions{
+		Duration:     4 * time.Second,
+		Concurrency:  3,
+		SearchWindow: 400 * time.Millisecond,
+		MinSilence:   500 * time.Millisecond,
+		NoiseLevel:   -35,
+		Overlap:      time.Second,
+	}
+	if got != want {
+		t.Errorf("withDefaults() = %+v, want %+v", got, want)
+	}
+}
+
+// fakeChunkTranscoder 不依赖 ffmpeg 的 ChunkTranscoder 和 TrimTranscoder
+type fakeChunkTranscoder struct {
+	duration time.Duration
+	silences []types.Silence
+
+	mu       sync.Mutex
+	ranges   []chunkSpan
+	segments [][]types.TimeRange
+	outputs  []*fakeAudio // TranscodeSegments 返回的音频
+}
+
+func (f *fakeChunkTranscoder) Transcode(ctx context.Context, in TranscodeInput) (*TranscodeOutput, error) {
+	return &TranscodeOutput{Audio: streamAudio{strings.NewReader("whole")}, Size: 5, Format: "aac"}, nil
+}
+
+func (f *fakeChunkTranscoder) Probe(ctx context.Context, path string) (*types.MediaInfo, error) {
+	return &types.MediaInfo{Duration: f.duration, AudioStreams: []types.AudioStream{{Codec: "aac"}}}, nil
+}
+
+func (f *fakeChunkTranscoder) DetectSilence(ctx context.Context, path string, noise float64, minDuration time.Duration) ([]types.Silence, error) {
+	return f.silences, nil
+}
+
+func (f *fakeChunkTranscoder) TranscodeRange(ctx context.Context, path string, start, duration time.Duration) (*TranscodeOutput, error) {
+	f.mu.Lock()
+	f.ranges = append(f.ranges, chunkSpan{Start: start, End: start + duration})
+	f.mu.Unlock()
+	data := fmt.Sprintf("%d-%d", start.Milliseconds(), duration.Milliseconds())
+	return &TranscodeOutput{Audio: streamAudio{strings.NewReader(data)}, Size: int64(len(data)), Format: "aac"}, nil
+}
+
+func (f *fakeChunkTranscoder) TranscodeSegments(ctx context.Context, in TranscodeInput, segments []types.TimeRange) (*TranscodeOutput, error) {
+	in.Progress(50, "音频提取中")
+	data := fmt.Sprint(segments)
+	audio := &fakeAudio{Reader: bytes.NewReader([]byte(data))}
+	f.mu.Lock()
+	f.segments = append(f.segments, segments)
+	f.outputs = append(f.outputs, audio)
+	f.mu.Unlock()
+	return &TranscodeOutput{Audio: audio, Size: int64(len(data)), Format: "aac"}, nil
+}
+
+// newChunkServer 识别结果为一句 5-6 秒的话，文本为上传的文件名
+func newChunkServer(t *testing.T) *httptest.Server {
+	return newFakeServerWithHook(t, nil, func(w http.ResponseWriter, r *http.Request) bool {
+		if r.URL.Path != "/task/result" {
+			return false
+		}
+		json.NewEncoder(w).Encode(types.ASRResponse{
+			Data: types.TaskResultResponse{
+				State:  types.StateComplete,
+				Result: fmt.Sprintf(`{"utterances":[{"start_time":5000,"end_time":6000,"transcript":%q}]}`, r.URL.Query().Get("task_id")),
+			},
+		})
+		return true
+	})
+}
+
+func TestTranscribe_Chunks(t *testing.T) {
+	server := newChunkServer(t)
+	defer server.Close()
+
+	input := filepath.Join(t.TempDir(), "video.mp4")
+	if err := os.WriteFile(input, mp4Data, 0644); err != nil {
+		t.Fatal(err)
+	}
+	transcoder := &fakeChunkTranscoder{
+		duration: 70 * time.Minute,
+		silences: []types.Silence{{Start: 29 * time.Minute, End: 31 * time.Minute}},
+	}
+	journalDir := t.TempDir()
+
+	var progress []types.ProgressInfo
+	result, err := Transcribe(context.Background(), input, ConvertOptions{
+		PollInterval: 0.001,
+		Client:       NewClient(WithBaseURL(server.URL), WithTranscoder(transcoder)),
+		JournalDir:   journalDir,
+		Chunk:        ChunkOptions{Duration: 30 * time.Minute, Concurrency: 2},
+		Progress: func(p types.ProgressInfo) {
+			progress = append(progress, p)
+		},
+	})
+	if err != nil {
+		t.Fatalf("Transcribe() error = %v", err)
+	}
+
+	sort.Slice(transcoder.ranges, func(a, b int) bool {
+		return transcoder.ranges[a].Start < transcoder.ranges[b].Start
+	})
+	wantRanges := []chunkSpan{
+		{Start: 0, End: 30 * time.Minute},
+		{Start: 30 * time.Minute, End: 60*time.Minute + time.Second},
+		{Start: 60*time.Minute - time.Second, End: 70 * time.Minute},
+	}
+	if !reflect.DeepEqual(transcoder.ranges, wantRanges) {
+		t.Errorf("TranscodeRange() = %v, want %v", transcoder.ranges, wantRanges)
+	}
+
+	want := []types.Utterance{
+		{StartTime: 5000, EndTime: 6000, Transcript: "video_001.aac"},
+		{StartTime: 1805000, EndTime: 1806000, Transcript: "video_002.aac"},
+		{StartTime: 3604000, EndTime: 3605000, Transcript: "video_003.aac"},
+	}
+	if !reflect.DeepEqual(result.Utterances, want) {
+		t.Errorf("Transcribe() = %+v, want %+v", result.Utterances, want)
+	}
+
+	if last := progress[len(progress)-1]; last.Stage != types.StageComplete {
+		t.Errorf("最后的进度 = %+v", last)
+	}
+	if entries, _ := os.ReadDir(journalDir); len(entries) != 0 {
+		t.Errorf("识别完成后应删除任务日志，剩余 %d 个", len(entries))
+	}
+}
+
+func TestTranscribe_ChunksShort(t *testing.T) {
+	server := newChunkServer(t)
+	defer server.Close()
+
+	transcoder := &fakeChunkTranscoder{duration: 10 * time.Minute}
+	result, err := TranscribeReader(context.Background(), strings.NewReader(string(mp4Data)), "stdin", "", ConvertOptions{
+		PollInterval: 0.001,
+		Client:       NewClient(WithBaseURL(server.URL), WithTranscoder(transcoder)),
+		Chunk:        ChunkOptions{Duration: 30 * time.Minute},
+	})
+	if err != nil {
+		t.Fatalf("TranscribeReader() error = %v", err)
+	}
+	if len(transcoder.ranges) != 0 {
+		t.Errorf("短音频不应分段: %v", transcoder.ranges)
+	}
+	if len(result.Utterances) != 1 || result.Utterances[0].Transcript != "stdin.aac" {
+		t.Errorf("TranscribeReader() = %+v", result.Utterances)
+	}
+}
+
+func TestTranscribe_ChunksError(t *testing.T) {
+	server := newFakeServerWithHook(t, nil, func(w http.ResponseWriter, r *http.Request) bool {
+		if r.URL.Path == "/task" {
+			json.NewEncoder(w).Encode(types.ASRResponse{Code: -400, Message: "参数错误"})
+			return true
+		}
+		return false
+	})
+	defer server.Close()
+
+	input := filepath.Join(t.TempDir(), "video.mp4")
+	if err := os.WriteFile(input, mp4Data, 0644); err != nil {
+		t.Fatal(err)
+	}
+	_, err := Transcribe(context.Background(), input, ConvertOptions{
+		PollInterval: 0.001,
+		Client:       NewClient(WithBaseURL(server.URL), WithTranscoder(&fakeChunkTranscoder{duration: 2 * time.Hour}), WithRetryPolicy(RetryPolicy{MaxAttempts: 1})),
+		Chunk:        ChunkOptions{Duration: 30 * time.Minute},
+	})
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) || apiErr.Code != -400 {
+		t.Errorf("Transcribe() error = %v, want *APIError", err)
+	}
+}
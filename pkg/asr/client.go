@@ -0,0 +1,357 @@
+package asr
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/562589540/bcut-asr-go/pkg/types"
+)
+
+// DefaultModelID 默认识别模型
+const DefaultModelID = "7"
+
+// Client 必剪接口客户端，保存接口地址、HTTP 客户端和请求头等配置
+//
+// Client 创建后不再修改，可以在多个 goroutine 中共享，每个文件通过 NewJob 创建独立的 Job。
+type Client struct {
+	baseURL           string
+	httpClient        *http.Client
+	header            http.Header
+	modelID           string
+	uploadConcurrency int
+	retryPolicy       RetryPolicy
+	transcoder        Transcoder
+}
+
+// Option 客户端选项
+type Option func(*Client)
+
+// WithBaseURL 设置接口基础URL，未设置时使用 types.GetAPIBaseURL()
+func WithBaseURL(baseURL string) Option {
+	return func(c *Client) {
+		c.baseURL = strings.TrimRight(baseURL, "/")
+	}
+}
+
+// WithHTTPClient 设置HTTP客户端
+func WithHTTPClient(httpClient *http.Client) Option {
+	return func(c *Client) {
+		if httpClient != nil {
+			c.httpClient = httpClient
+		}
+	}
+}
+
+// WithCookie 设置请求接口时携带的Cookie
+func WithCookie(cookie string) Option {
+	return WithHeader("Cookie", cookie)
+}
+
+// WithAuthorization 设置请求接口时携带的Authorization头
+func WithAuthorization(auth string) Option {
+	return WithHeader("Authorization", auth)
+}
+
+// WithUserAgent 设置User-Agent
+func WithUserAgent(userAgent string) Option {
+	return WithHeader("User-Agent", userAgent)
+}
+
+// WithHeader 设置额外的请求头，value 为空时删除该请求头
+func WithHeader(key, value string) Option {
+	return func(c *Client) {
+		if value == "" {
+			c.header.Del(key)
+			return
+		}
+		c.header.Set(key, value)
+	}
+}
+
+// WithModelID 设置识别模型ID，默认为 DefaultModelID
+func WithModelID(modelID string) Option {
+	return func(c *Client) {
+		if modelID != "" {
+			c.modelID = modelID
+		}
+	}
+}
+
+// WithUploadConcurrency 设置同时上传的分片数，默认为 1
+//
+// 从 io.Reader 顺序读取时每个并发上传占用一个分片大小的缓冲区。
+func WithUploadConcurrency(n int) Option {
+	return func(c *Client) {
+		if n > 0 {
+			c.uploadConcurrency = n
+		}
+	}
+}
+
+// WithTranscoder 设置提取音频的 Transcoder，默认为 FFmpegTranscoder
+func WithTranscoder(t Transcoder) Option {
+	return func(c *Client) {
+		if t != nil {
+			c.transcoder = t
+		}
+	}
+}
+
+// NewClient 创建客户端
+func NewClient(opts ...Option) *Client {
+	c := &Client{
+		httpClient:        &http.Client{},
+		header:            make(http.Header),
+		modelID:           DefaultModelID,
+		uploadConcurrency: 1,
+		retryPolicy:       DefaultRetryPolicy,
+		transcoder:        &FFmpegTranscoder{},
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
+}
+
+// NewJob 创建一个识别任务
+func (c *Client) NewJob(ctx context.Context) *Job {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	return &Job{
+		client: c,
+		ctx:    ctx,
+	}
+}
+
+// ModelID 返回识别模型ID
+func (c *Client) ModelID() string {
+	return c.modelID
+}
+
+// BaseURL 返回接口基础URL
+func (c *Client) BaseURL() string {
+	if c.baseURL != "" {
+		return c.baseURL
+	}
+	return types.GetAPIBaseURL()
+}
+
+// newRequest 创建接口请求并设置请求头
+func (c *Client) newRequest(ctx context.Context, method, path string, body io.Reader) (*http.Request, error) {
+	req, err := http.NewRequestWithContext(ctx, method, c.BaseURL()+path, body)
+	if err != nil {
+		return nil, fmt.Errorf("create request failed: %w", err)
+	}
+	for key, values := range c.header {
+		req.Header[key] = append([]string(nil), values...)
+	}
+	return req, nil
+}
+
+// newUploadRequest 创建分片上传请求，上传地址为预签名URL，只携带User-Agent
+func (c *Client) newUploadRequest(ctx context.Context, uploadURL string, body io.Reader) (*http.Request, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPut, uploadURL, body)
+	if err != nil {
+		return nil, err
+	}
+	if ua := c.header.Get("User-Agent"); ua != "" {
+		req.Header.Set("User-Agent", ua)
+	}
+	return req, nil
+}
+
+// apiResponse 接口响应，Data 延迟解析
+type apiResponse struct {
+	Code    int             `json:"code"`
+	Message string          `json:"message"`
+	Data    json.RawMessage `json:"data"`
+}
+
+// call 发送接口请求并将响应中的 data 解析到 out，失败时按重试策略重发
+func (c *Client) call(step Step, req *http.Request, out interface{}) error {
+	ctx := req.Context()
+	return c.retryPolicy.retry(ctx, func(attempt int) (bool, error) {
+		r := req
+		if attempt > 1 && req.GetBody != nil {
+			body, err := req.GetBody()
+			if err != nil {
+				return false, err
+			}
+			r = req.Clone(ctx)
+			r.Body = body
+		}
+		return c.do(step, r, out)
+	})
+}
+
+// do 发送一次接口请求，返回错误是否可重试
+func (c *Client) do(step Step, req *http.Request, out interface{}) (bool, error) {
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return retryableNetError(req.Context(), err), &APIError{Step: step, Err: err}
+	}
+	defer resp.Body.Close()
+
+	if c.retryPolicy.retryableStatus(resp.StatusCode) {
+		return true, &APIError{Step: step, StatusCode: resp.StatusCode}
+	}
+
+	var result apiResponse
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		// 2xx 的非JSON响应多为网关错误页，可以重试；其他状态码（如 401、404 的错误页）直接返回状态码
+		if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+			return false, &APIError{Step: step, StatusCode: resp.StatusCode}
+		}
+		return true, &APIError{Step: step, StatusCode: resp.StatusCode, Err: fmt.Errorf("decode response failed (status %d): %w", resp.StatusCode, err)}
+	}
+
+	if result.Code != 0 {
+		return c.retryPolicy.retryableCode(result.Code), &APIError{
+			Step:       step,
+			StatusCode: resp.StatusCode,
+			Code:       result.Code,
+			Message:    result.Message,
+		}
+	}
+
+	if out == nil || len(result.Data) == 0 {
+		return false, nil
+	}
+	if err := json.Unmarshal(result.Data, out); err != nil {
+		return false, &APIError{Step: step, StatusCode: resp.StatusCode, Err: fmt.Errorf("parse response data failed: %w", err)}
+	}
+	return false, nil
+}
+
+// createResource 申请上传，返回分片上传地址
+func (c *Client) createResource(ctx context.Context, name, format string, size int64) (*types.ResourceCreateResponse, error) {
+	formData := url.Values{
+		"type":               {"2"},
+		"name":               {name},
+		"size":               {strconv.FormatInt(size, 10)},
+		"resource_file_type": {format},
+		"model_id":           {c.modelID},
+	}
+
+	req, err := c.newRequest(ctx, http.MethodPost, types.APIReqUpload, strings.NewReader(formData.Encode()))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	var createResp types.ResourceCreateResponse
+	if err := c.call(StepCreateResource, req, &createResp); err != nil {
+		return nil, err
+	}
+	return &createResp, nil
+}
+
+// uploadPart 上传单个分片，返回 etag，失败时只重传该分片
+//
+// body 在每次尝试时调用，返回从分片开头读取的新 Reader。
+func (c *Client) uploadPart(ctx context.Context, uploadURL string, body func() io.Reader, size int64, part int) (string, error) {
+	var etag string
+	err := c.retryPolicy.retry(ctx, func(attempt int) (bool, error) {
+		req, err := c.newUploadRequest(ctx, uploadURL, body())
+		if err != nil {
+			return false, &UploadError{Part: part, Err: err}
+		}
+		// 预签名地址不接受分块传输，需明确设置长度
+		req.ContentLength = size
+		if size == 0 {
+			req.Body = http.NoBody
+		}
+
+		resp, err := c.httpClient.Do(req)
+		if err != nil {
+			return retryableNetError(ctx, err), &UploadError{Part: part, Err: err}
+		}
+		io.Copy(io.Discard, resp.Body)
+		resp.Body.Close()
+
+		if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+			return c.retryPolicy.retryableStatus(resp.StatusCode), &UploadError{Part: part, StatusCode: resp.StatusCode}
+		}
+
+		etag = resp.Header.Get("Etag")
+		if etag == "" {
+			return false, &UploadError{Part: part, StatusCode: resp.StatusCode, Err: ErrNoETag}
+		}
+		return false, nil
+	})
+	return etag, err
+}
+
+// commitResource 提交上传，返回资源下载地址
+func (c *Client) commitResource(ctx context.Context, resource *types.ResourceCreateResponse, etags []string) (*types.ResourceCompleteResponse, error) {
+	formData := url.Values{
+		"in_boss_key": {resource.InBossKey},
+		"resource_id": {resource.ResourceID},
+		"etags":       {strings.Join(etags, ",")},
+		"upload_id":   {resource.UploadID},
+		"model_id":    {c.modelID},
+	}
+
+	req, err := c.newRequest(ctx, http.MethodPost, types.APICommitUpload, strings.NewReader(formData.Encode()))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	var completeResp types.ResourceCompleteResponse
+	if err := c.call(StepCommitResource, req, &completeResp); err != nil {
+		return nil, err
+	}
+	return &completeResp, nil
+}
+
+// CreateTask 为已上传的资源创建识别任务，resource 为上传完成后的资源地址（Job.DownloadURL）
+func (c *Client) CreateTask(ctx context.Context, resource string) (string, error) {
+	reqData := map[string]interface{}{
+		"resource": resource,
+		"model_id": c.modelID,
+	}
+
+	reqBody, err := json.Marshal(reqData)
+	if err != nil {
+		return "", err
+	}
+
+	req, err := c.newRequest(ctx, http.MethodPost, types.APICreateTask, bytes.NewReader(reqBody))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	var taskResp types.TaskCreateResponse
+	if err := c.call(StepCreateTask, req, &taskResp); err != nil {
+		return "", err
+	}
+	return taskResp.TaskID, nil
+}
+
+// queryTask 查询任务状态
+func (c *Client) queryTask(ctx context.Context, taskID string) (*types.TaskResultResponse, error) {
+	query := url.Values{
+		"model_id": {c.modelID},
+		"task_id":  {taskID},
+	}
+	req, err := c.newRequest(ctx, http.MethodGet, types.APIQueryResult+"?"+query.Encode(), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var taskResult types.TaskResultResponse
+	if err := c.call(StepQueryResult, req, &taskResult); err != nil {
+		return nil, err
+	}
+	return &taskResult, nil
+}
@@ -0,0 +1,82 @@
+package asr
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/562589540/bcut-asr-go/pkg/types"
+)
+
+func TestClient_Options(t *testing.T) {
+	newServer := func(taskID string) *httptest.Server {
+		return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if got := r.Header.Get("Cookie"); got != "SESSDATA=test" {
+				t.Errorf("Cookie = %q, want %q", got, "SESSDATA=test")
+			}
+			if got := r.Header.Get("User-Agent"); got != "bcut-test" {
+				t.Errorf("User-Agent = %q, want %q", got, "bcut-test")
+			}
+			if got := r.Header.Get("X-Test"); got != "1" {
+				t.Errorf("X-Test = %q, want %q", got, "1")
+			}
+
+			var body map[string]interface{}
+			if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+				t.Errorf("decode request failed: %v", err)
+			}
+			if body["model_id"] != "8" {
+				t.Errorf("model_id = %v, want %v", body["model_id"], "8")
+			}
+
+			json.NewEncoder(w).Encode(types.ASRResponse{
+				Code: 0,
+				Data: types.TaskCreateResponse{TaskID: taskID},
+			})
+		}))
+	}
+
+	staging := newServer("staging-task")
+	defer staging.Close()
+	production := newServer("production-task")
+	defer production.Close()
+
+	opts := []Option{
+		WithCookie("SESSDATA=test"),
+		WithUserAgent("bcut-test"),
+		WithHeader("X-Test", "1"),
+		WithModelID("8"),
+	}
+	clients := map[string]*Client{
+		"staging-task":    NewClient(append(opts, WithBaseURL(staging.URL))...),
+		"production-task": NewClient(append(opts, WithBaseURL(production.URL+"/"))...),
+	}
+
+	for want, client := range clients {
+		job := client.NewJob(context.Background())
+		job.downloadURL = "http://test.com/download"
+
+		taskID, err := job.CreateTask()
+		if err != nil {
+			t.Fatalf("CreateTask() error = %v", err)
+		}
+		if taskID != want {
+			t.Errorf("taskID = %v, want %v", taskID, want)
+		}
+	}
+}
+
+func TestClient_BaseURLFallback(t *testing.T) {
+	origBaseURL := types.GetAPIBaseURL()
+	types.SetAPIBaseURL("http://global.test")
+	defer func() { types.SetAPIBaseURL(origBaseURL) }()
+
+	if got := NewClient().BaseURL(); got != "http://global.test" {
+		t.Errorf("BaseURL() = %v, want %v", got, "http://global.test")
+	}
+	if got := NewClient(WithBaseURL("http://local.test")).BaseURL(); got != "http://local.test" {
+		t.Errorf("BaseURL() = %v, want %v", got, "http://local.test")
+	}
+}
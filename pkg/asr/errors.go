@@ -0,0 +1,118 @@
+package asr
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Step 接口调用步骤
+type Step string
+
+const (
+	StepCreateResource Step = "resource/create"          // 申请上传
+	StepUploadPart     Step = "upload"                   // 分片上传
+	StepCommitResource Step = "resource/create/complete" // 提交上传
+	StepCreateTask     Step = "task"                     // 创建任务
+	StepQueryResult    Step = "task/result"              // 查询结果
+)
+
+var (
+	// ErrNoETag 分片上传响应中没有 Etag
+	ErrNoETag = errors.New("no etag in response")
+	// ErrNoAudio 没有可上传的音频数据
+	ErrNoAudio = errors.New("没有音频数据")
+	// ErrNotMedia 输入不是音视频，如文本、图片或文档
+	ErrNotMedia = errors.New("不是音视频文件")
+)
+
+// APIError 接口请求失败：网络错误、异常状态码、无法解析的响应或非 0 的接口错误码
+type APIError struct {
+	Step       Step   // 失败的步骤
+	StatusCode int    // HTTP 状态码，请求未完成时为 0
+	Code       int    // 接口返回的错误码，响应无法解析时为 0
+	Message    string // 接口返回的错误信息
+	Err        error  // 底层错误，接口返回错误码时为 nil
+}
+
+func (e *APIError) Error() string {
+	switch {
+	case e.Code != 0:
+		return fmt.Sprintf("%s: API error: %d - %s", e.Step, e.Code, e.Message)
+	case e.Err != nil:
+		return fmt.Sprintf("%s: %v", e.Step, e.Err)
+	default:
+		return fmt.Sprintf("%s: unexpected status: %d", e.Step, e.StatusCode)
+	}
+}
+
+func (e *APIError) Unwrap() error {
+	return e.Err
+}
+
+// UploadError 分片上传失败
+type UploadError struct {
+	Part       int   // 分片序号，从 0 开始
+	StatusCode int   // HTTP 状态码，请求未完成时为 0
+	Err        error // 底层错误
+}
+
+func (e *UploadError) Error() string {
+	if e.Err != nil {
+		return fmt.Sprintf("upload part %d failed: %v", e.Part, e.Err)
+	}
+	return fmt.Sprintf("upload part %d failed: unexpected status: %d", e.Part, e.StatusCode)
+}
+
+func (e *UploadError) Unwrap() error {
+	return e.Err
+}
+
+// TaskFailedError 服务端识别失败
+type TaskFailedError struct {
+	TaskID string // 任务ID
+	Remark string // 服务端返回的失败原因
+}
+
+func (e *TaskFailedError) Error() string {
+	return fmt.Sprintf("task %s failed: %s", e.TaskID, e.Remark)
+}
+
+// TranscodeError 转码提取音频失败
+type TranscodeError struct {
+	Stderr string // ffmpeg 输出的错误信息
+	Err    error  // 底层错误
+}
+
+func (e *TranscodeError) Error() string {
+	msg := fmt.Sprintf("ffmpeg执行失败: %v", e.Err)
+	if stderr := strings.TrimSpace(e.Stderr); stderr != "" {
+		msg += ": " + stderr
+	}
+	return msg
+}
+
+func (e *TranscodeError) Unwrap() error {
+	return e.Err
+}
+
+// stderrTail 保留命令错误输出的最后若干行
+type stderrTail struct {
+	lines []string
+}
+
+const maxStderrLines = 20
+
+func (t *stderrTail) add(line string) {
+	if strings.TrimSpace(line) == "" {
+		return
+	}
+	t.lines = append(t.lines, line)
+	if len(t.lines) > maxStderrLines {
+		t.lines = t.lines[len(t.lines)-maxStderrLines:]
+	}
+}
+
+func (t *stderrTail) String() string {
+	return strings.Join(t.lines, "\n")
+}
@@ -0,0 +1,97 @@
+package asr
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/562589540/bcut-asr-go/pkg/types"
+)
+
+func TestErrors_API(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(types.ASRResponse{Code: -101, Message: "账号未登录"})
+	}))
+	defer server.Close()
+
+	job := NewClient(WithBaseURL(server.URL), WithRetryPolicy(NoRetry)).NewJob(context.Background())
+	job.SetReaderAt(bytes.NewReader(mp3Data), int64(len(mp3Data)), "test.mp3", "mp3")
+
+	err := job.Upload()
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) {
+		t.Fatalf("Upload() error = %v, want *APIError", err)
+	}
+	if apiErr.Step != StepCreateResource || apiErr.Code != -101 || apiErr.Message != "账号未登录" {
+		t.Errorf("APIError = %+v", apiErr)
+	}
+}
+
+func TestErrors_Upload(t *testing.T) {
+	var server *httptest.Server
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/resource/create" {
+			json.NewEncoder(w).Encode(types.ASRResponse{
+				Code: 0,
+				Data: types.ResourceCreateResponse{UploadURLs: []string{server.URL + "/upload"}, PerSize: 1024},
+			})
+		}
+	}))
+	defer server.Close()
+
+	job := NewClient(WithBaseURL(server.URL), WithRetryPolicy(NoRetry)).NewJob(context.Background())
+	job.SetReaderAt(bytes.NewReader(mp3Data), int64(len(mp3Data)), "test.mp3", "mp3")
+
+	err := job.Upload()
+	var uploadErr *UploadError
+	if !errors.As(err, &uploadErr) || uploadErr.Part != 0 {
+		t.Fatalf("Upload() error = %v, want *UploadError for part 0", err)
+	}
+	if !errors.Is(err, ErrNoETag) {
+		t.Errorf("Upload() error = %v, want ErrNoETag", err)
+	}
+}
+
+func TestErrors_TaskFailed(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(types.ASRResponse{
+			Code: 0,
+			Data: types.TaskResultResponse{State: types.StateError, Remark: "音频无法识别"},
+		})
+	}))
+	defer server.Close()
+
+	job := NewClient(WithBaseURL(server.URL)).NewJob(context.Background())
+	job.taskID = "test-task"
+
+	_, err := job.QueryResult()
+	var taskErr *TaskFailedError
+	if !errors.As(err, &taskErr) {
+		t.Fatalf("QueryResult() error = %v, want *TaskFailedError", err)
+	}
+	if taskErr.TaskID != "test-task" || taskErr.Remark != "音频无法识别" {
+		t.Errorf("TaskFailedError = %+v", taskErr)
+	}
+}
+
+func TestErrors_Transcode(t *testing.T) {
+	tmpFile := filepath.Join(t.TempDir(), "test.mp4")
+	if err := os.WriteFile(tmpFile, append(mp4Data, "not a video"...), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	job := New(context.Background())
+	defer job.Close()
+
+	err := job.SetData(tmpFile)
+	var transcodeErr *TranscodeError
+	if !errors.As(err, &transcodeErr) {
+		t.Fatalf("SetData() error = %v, want *TranscodeError", err)
+	}
+}
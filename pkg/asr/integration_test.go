@@ -142,9 +142,10 @@ func TestConvertToSubtitle(t *testing.T) {
 		{
 			name: "指定输出目录",
 			options: ConvertOptions{
-				Format:     "srt",
-				Interval:   5.0,
-				OutputPath: tempDir,
+				Format:       "srt",
+				Interval:     5.0,
+				PollInterval: 5.0,
+				OutputPath:   tempDir,
 				Progress: func(info types.ProgressInfo) {
 					t.Logf("进度: %s %d%%, %s", info.Stage, info.Current, info.Description)
 				},
@@ -153,9 +154,10 @@ func TestConvertToSubtitle(t *testing.T) {
 		{
 			name: "指定完整输出路径",
 			options: ConvertOptions{
-				Format:     "lrc",
-				Interval:   5.0,
-				OutputPath: filepath.Join(tempDir, "output.lrc"),
+				Format:       "lrc",
+				Interval:     5.0,
+				PollInterval: 5.0,
+				OutputPath:   filepath.Join(tempDir, "output.lrc"),
 				Progress: func(info types.ProgressInfo) {
 					t.Logf("进度: %s %d%%, %s", info.Stage, info.Current, info.Description)
 				},
@@ -0,0 +1,199 @@
+package asr
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/562589540/bcut-asr-go/pkg/types"
+)
+
+// JobState 识别任务各步骤的结果，保存到 Journal 后中断的任务可以从上次完成的步骤继续
+type JobState struct {
+	ModelID     string    `json:"model_id"`
+	SoundName   string    `json:"sound_name"`
+	SoundFormat string    `json:"sound_format"`
+	SoundSize   int64     `json:"sound_size"`
+	ResourceID  string    `json:"resource_id,omitempty"`
+	InBossKey   string    `json:"in_boss_key,omitempty"`
+	UploadID    string    `json:"upload_id,omitempty"`
+	UploadURLs  []string  `json:"upload_urls,omitempty"`
+	PerSize     int       `json:"per_size,omitempty"`
+	ETags       []string  `json:"etags,omitempty"` // 按分片顺序排列，未完成的分片为空
+	DownloadURL string    `json:"download_url,omitempty"`
+	TaskID      string    `json:"task_id,omitempty"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+// resource 已申请的上传，未申请时返回 nil
+func (s *JobState) resource() *types.ResourceCreateResponse {
+	if s.UploadID == "" || len(s.UploadURLs) == 0 {
+		return nil
+	}
+	return &types.ResourceCreateResponse{
+		ResourceID: s.ResourceID,
+		InBossKey:  s.InBossKey,
+		UploadID:   s.UploadID,
+		UploadURLs: s.UploadURLs,
+		PerSize:    s.PerSize,
+	}
+}
+
+// setResource 记录申请到的上传，清空之前的分片和后续步骤
+func (s *JobState) setResource(resource *types.ResourceCreateResponse) {
+	s.ResourceID = resource.ResourceID
+	s.InBossKey = resource.InBossKey
+	s.UploadID = resource.UploadID
+	s.UploadURLs = resource.UploadURLs
+	s.PerSize = resource.PerSize
+	s.ETags = make([]string, len(resource.UploadURLs))
+	s.DownloadURL = ""
+	s.TaskID = ""
+}
+
+// Journal 以 JSON 文件保存 JobState 的目录，每个 key 对应一个文件
+type Journal struct {
+	dir string
+	mu  sync.Mutex
+}
+
+// NewJournal 创建任务日志，dir 不存在时自动创建
+func NewJournal(dir string) (*Journal, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, fmt.Errorf("创建任务日志目录失败: %w", err)
+	}
+	return &Journal{dir: dir}, nil
+}
+
+func (jn *Journal) path(key string) string {
+	return filepath.Join(jn.dir, key+".json")
+}
+
+// Load 读取 key 对应的任务状态，不存在时返回 nil, nil
+func (jn *Journal) Load(key string) (*JobState, error) {
+	jn.mu.Lock()
+	defer jn.mu.Unlock()
+
+	data, err := os.ReadFile(jn.path(key))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var state JobState
+	if err := json.Unmarshal(data, &state); err != nil {
+		return nil, fmt.Errorf("解析任务日志失败: %w", err)
+	}
+	return &state, nil
+}
+
+// Save 保存任务状态，先写临时文件再重命名，避免中断时留下不完整的文件
+func (jn *Journal) Save(key string, state *JobState) error {
+	jn.mu.Lock()
+	defer jn.mu.Unlock()
+
+	state.UpdatedAt = time.Now()
+	data, err := json.MarshalIndent(state, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(jn.dir, key+".*.tmp")
+	if err != nil {
+		return err
+	}
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	return os.Rename(tmp.Name(), jn.path(key))
+}
+
+// Remove 删除 key 对应的任务状态
+func (jn *Journal) Remove(key string) error {
+	jn.mu.Lock()
+	defer jn.mu.Unlock()
+
+	err := os.Remove(jn.path(key))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	return err
+}
+
+// HashFile 计算文件内容的 SHA-256，作为任务日志的 key
+func HashFile(path string) (string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer file.Close()
+
+	h := sha256.New()
+	if _, err := io.Copy(h, file); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// WithJournal 将每个步骤的结果记录到 journal，key 相同的任务从上次完成的步骤继续：
+// 剩余分片、提交上传、创建任务或查询结果
+//
+// key 通常为输入文件的 HashFile。
+func (j *Job) WithJournal(journal *Journal, key string) *Job {
+	j.journal = journal
+	j.journalKey = key
+	return j
+}
+
+// loadState 读取与当前音频匹配的任务状态，没有可继续的状态时返回新状态
+func (j *Job) loadState() (*JobState, error) {
+	if j.journal != nil {
+		state, err := j.journal.Load(j.journalKey)
+		if err != nil {
+			return nil, err
+		}
+		if state != nil &&
+			state.ModelID == j.client.modelID &&
+			state.SoundFormat == j.soundFormat &&
+			state.SoundSize == j.soundSize {
+			return state, nil
+		}
+	}
+	return j.newState(), nil
+}
+
+// newState 当前音频的空白任务状态
+func (j *Job) newState() *JobState {
+	return &JobState{
+		ModelID:     j.client.modelID,
+		SoundName:   j.soundName,
+		SoundFormat: j.soundFormat,
+		SoundSize:   j.soundSize,
+	}
+}
+
+// saveState 保存任务状态，未设置任务日志时忽略
+func (j *Job) saveState() error {
+	if j.journal == nil || j.state == nil {
+		return nil
+	}
+	if err := j.journal.Save(j.journalKey, j.state); err != nil {
+		return fmt.Errorf("保存任务日志失败: %w", err)
+	}
+	return nil
+}
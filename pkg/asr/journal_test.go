@@ -0,0 +1,210 @@
+package asr
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/562589540/bcut-asr-go/pkg/types"
+)
+
+func TestJob_Journal(t *testing.T) {
+	var (
+		mu       sync.Mutex
+		calls    = make(map[string]int)
+		failPart = true
+	)
+	commits := make(chan string, 1)
+	server := newFakeServerWithHook(t, commits, func(w http.ResponseWriter, r *http.Request) bool {
+		mu.Lock()
+		defer mu.Unlock()
+		calls[r.URL.Path]++
+		if failPart && r.URL.Path == "/upload/test.mp3/1" {
+			w.WriteHeader(http.StatusBadGateway)
+			return true
+		}
+		return false
+	})
+	defer server.Close()
+
+	journal, err := NewJournal(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	client := NewClient(WithBaseURL(server.URL), WithRetryPolicy(NoRetry))
+	newJob := func() *Job {
+		job := client.NewJob(context.Background()).WithJournal(journal, "test-key")
+		job.SetReader(strings.NewReader("aaaabbbbcccc"), 12, "test.mp3", "mp3")
+		return job
+	}
+
+	// 第一次运行：第 2 个分片失败
+	if err := newJob().Upload(); err == nil {
+		t.Fatal("Upload() should fail")
+	}
+	saved, err := journal.Load("test-key")
+	if err != nil || saved == nil {
+		t.Fatalf("Load() = %v, %v", saved, err)
+	}
+	if got := strings.Join(saved.ETags, ","); got != "aaaa,," {
+		t.Errorf("saved etags = %q, want %q", got, "aaaa,,")
+	}
+
+	// 第二次运行：只上传剩余分片
+	failPart = false
+	job := newJob()
+	if err := job.Upload(); err != nil {
+		t.Fatalf("Upload() error = %v", err)
+	}
+	if got := <-commits; got != "aaaa,bbbb,cccc" {
+		t.Errorf("etags = %q, want %q", got, "aaaa,bbbb,cccc")
+	}
+	for path, want := range map[string]int{
+		"/resource/create":   1,
+		"/upload/test.mp3/0": 1,
+		"/upload/test.mp3/1": 2,
+		"/upload/test.mp3/2": 1,
+	} {
+		if calls[path] != want {
+			t.Errorf("calls[%s] = %d, want %d", path, calls[path], want)
+		}
+	}
+	taskID, err := job.CreateTask()
+	if err != nil {
+		t.Fatalf("CreateTask() error = %v", err)
+	}
+
+	// 第三次运行：跳过上传和创建任务
+	job = newJob()
+	if err := job.Upload(); err != nil {
+		t.Fatalf("Upload() error = %v", err)
+	}
+	resumed, err := job.CreateTask()
+	if err != nil || resumed != taskID {
+		t.Errorf("CreateTask() = %v, %v, want %v", resumed, err, taskID)
+	}
+	if calls["/resource/create/complete"] != 1 || calls["/task"] != 1 {
+		t.Errorf("calls = %v, want a single commit and task", calls)
+	}
+}
+
+func TestJob_JournalExpired(t *testing.T) {
+	var (
+		mu           sync.Mutex
+		calls        = make(map[string]int)
+		expireTask   bool
+		expireUpload bool
+	)
+	server := newFakeServerWithHook(t, nil, func(w http.ResponseWriter, r *http.Request) bool {
+		mu.Lock()
+		defer mu.Unlock()
+		calls[r.URL.Path]++
+		switch {
+		case expireTask && r.URL.Path == "/task/result":
+			expireTask = false
+			json.NewEncoder(w).Encode(types.ASRResponse{Code: -404, Message: "任务不存在"})
+			return true
+		case expireUpload && r.URL.Path == "/task":
+			expireUpload = false
+			json.NewEncoder(w).Encode(types.ASRResponse{Code: -400, Message: "资源已过期"})
+			return true
+		}
+		return false
+	})
+	defer server.Close()
+
+	journal, err := NewJournal(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	client := NewClient(WithBaseURL(server.URL), WithRetryPolicy(NoRetry))
+	newJob := func() *Job {
+		job := client.NewJob(context.Background()).WithJournal(journal, "test-key")
+		job.SetReader(strings.NewReader("aaaabbbb"), 8, "test.mp3", "mp3")
+		return job
+	}
+
+	// 第一次运行：上传并创建任务后中断
+	job := newJob()
+	if err := job.Upload(); err != nil {
+		t.Fatalf("Upload() error = %v", err)
+	}
+	if _, err := job.CreateTask(); err != nil {
+		t.Fatalf("CreateTask() error = %v", err)
+	}
+
+	// 第二次运行：保存的任务已过期，重新创建任务
+	expireTask = true
+	job = newJob()
+	if err := job.Upload(); err != nil {
+		t.Fatalf("Upload() error = %v", err)
+	}
+	if _, err := job.CreateTask(); err != nil {
+		t.Fatalf("CreateTask() error = %v", err)
+	}
+	if _, err := job.Wait(time.Millisecond); err != nil {
+		t.Fatalf("Wait() error = %v", err)
+	}
+	if calls["/task"] != 2 || calls["/resource/create"] != 1 {
+		t.Errorf("calls = %v, want 2 tasks and a single upload", calls)
+	}
+
+	// 另一个文件上传后、创建任务前中断，再次运行时保存的资源已过期，重新上传
+	uploadJob := func() *Job {
+		job := client.NewJob(context.Background()).WithJournal(journal, "upload-key")
+		job.SetReader(strings.NewReader("ccccdddd"), 8, "other.mp3", "mp3")
+		return job
+	}
+	if err := uploadJob().Upload(); err != nil {
+		t.Fatalf("Upload() error = %v", err)
+	}
+	expireUpload = true
+	job = uploadJob()
+	if err := job.Upload(); err != nil {
+		t.Fatalf("Upload() error = %v", err)
+	}
+	if _, err := job.CreateTask(); err != nil {
+		t.Fatalf("CreateTask() error = %v", err)
+	}
+	if calls["/resource/create"] != 3 || calls["/task"] != 4 {
+		t.Errorf("calls = %v, want 3 uploads and 4 task requests", calls)
+	}
+	if saved, err := journal.Load("upload-key"); err != nil || saved.TaskID == "" || saved.DownloadURL == "" {
+		t.Errorf("journal = %+v, %v", saved, err)
+	}
+}
+
+func TestConvertToSubtitle_Journal(t *testing.T) {
+	server := newFakeServer(t, nil)
+	defer server.Close()
+
+	dir := t.TempDir()
+	input := filepath.Join(dir, "test.mp3")
+	if err := os.WriteFile(input, []byte("ID3test"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	journalDir := filepath.Join(dir, "journal")
+
+	err := ConvertToSubtitle(input, ConvertOptions{
+		Format:       "txt",
+		PollInterval: 0.001,
+		Client:       NewClient(WithBaseURL(server.URL)),
+		JournalDir:   journalDir,
+	})
+	if err != nil {
+		t.Fatalf("ConvertToSubtitle() error = %v", err)
+	}
+
+	if data, err := os.ReadFile(filepath.Join(dir, "test.txt")); err != nil || string(data) != "test.mp3\n" {
+		t.Errorf("output = %q, %v", data, err)
+	}
+	if entries, _ := os.ReadDir(journalDir); len(entries) != 0 {
+		t.Errorf("journal not removed: %v", entries)
+	}
+}
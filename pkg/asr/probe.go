@@ -0,0 +1,234 @@
+package asr
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/562589540/bcut-asr-go/pkg/types"
+	"github.com/562589540/bcut-asr-go/pkg/utils"
+)
+
+// ProbeMedia 使用 ffprobe 读取媒体文件的时长和音频流信息
+func ProbeMedia(ctx context.Context, path string) (*types.MediaInfo, error) {
+	return probeMedia(ctx, "ffprobe", path)
+}
+
+func probeMedia(ctx context.Context, ffprobe, path string) (*types.MediaInfo, error) {
+	cmd := utils.RunCommandContext(ctx, ffprobe,
+		"-v", "error",
+		"-print_format", "json",
+		"-show_format",
+		"-show_streams",
+		path,
+	)
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return nil, fmt.Errorf("ffprobe执行失败: %w: %s", err, msg)
+		}
+		return nil, fmt.Errorf("ffprobe执行失败: %w", err)
+	}
+	return parseProbeOutput(stdout.Bytes())
+}
+
+// probeOutput ffprobe -print_format json 的输出，数值字段多为字符串
+type probeOutput struct {
+	Streams []struct {
+		Index         int    `json:"index"`
+		CodecType     string `json:"codec_type"`
+		CodecName     string `json:"codec_name"`
+		Profile       string `json:"profile"`
+		SampleRate    string `json:"sample_rate"`
+		Channels      int    `json:"channels"`
+		ChannelLayout string `json:"channel_layout"`
+		BitRate       string `json:"bit_rate"`
+		Duration      string `json:"duration"`
+	} `json:"streams"`
+	Format struct {
+		FormatName string `json:"format_name"`
+		Duration   string `json:"duration"`
+		Size       string `json:"size"`
+		BitRate    string `json:"bit_rate"`
+	} `json:"format"`
+}
+
+func parseProbeOutput(data []byte) (*types.MediaInfo, error) {
+	var out probeOutput
+	if err := json.Unmarshal(data, &out); err != nil {
+		return nil, fmt.Errorf("解析ffprobe输出失败: %w", err)
+	}
+
+	info := &types.MediaInfo{
+		FormatName: out.Format.FormatName,
+		Duration:   parseSeconds(out.Format.Duration),
+		Size:       parseInt(out.Format.Size),
+		BitRate:    parseInt(out.Format.BitRate),
+	}
+	for _, s := range out.Streams {
+		if s.CodecType != "audio" {
+			continue
+		}
+		stream := types.AudioStream{
+			Index:         s.Index,
+			Codec:         s.CodecName,
+			Profile:       s.Profile,
+			SampleRate:    int(parseInt(s.SampleRate)),
+			Channels:      s.Channels,
+			ChannelLayout: s.ChannelLayout,
+			BitRate:       parseInt(s.BitRate),
+			Duration:      parseSeconds(s.Duration),
+		}
+		info.AudioStreams = append(info.AudioStreams, stream)
+		// 部分容器（如 ADTS）没有总时长，使用音频流的时长
+		if info.Duration == 0 {
+			info.Duration = stream.Duration
+		}
+	}
+	return info, nil
+}
+
+// parseSeconds 解析以秒为单位的小数，无效值（如 "N/A"）返回 0
+func parseSeconds(s string) time.Duration {
+	f, err := strconv.ParseFloat(s, 64)
+	if err != nil || f <= 0 || math.IsInf(f, 0) || math.IsNaN(f) {
+		return 0
+	}
+	return time.Duration(f * float64(time.Second))
+}
+
+func parseInt(s string) int64 {
+	n, err := strconv.ParseInt(s, 10, 64)
+	if err != nil {
+		return 0
+	}
+	return n
+}
+
+// parseFFmpegTime 从 ffmpeg 的输出行中读取已处理的时间位置
+//
+// 支持 -progress 输出的 out_time_us= / out_time_ms=（两者单位均为微秒）/ out_time=，
+// 以及统计信息中的 time=hh:mm:ss.xx。
+func parseFFmpegTime(line string) (time.Duration, bool) {
+	line = strings.TrimSpace(line)
+	for _, key := range []string{"out_time_us=", "out_time_ms="} {
+		if strings.HasPrefix(line, key) {
+			us, err := strconv.ParseInt(strings.TrimPrefix(line, key), 10, 64)
+			if err != nil || us < 0 {
+				return 0, false
+			}
+			return time.Duration(us) * time.Microsecond, true
+		}
+	}
+
+	var clock string
+	if strings.HasPrefix(line, "out_time=") {
+		clock = strings.TrimPrefix(line, "out_time=")
+	} else if i := strings.Index(line, "time="); i >= 0 && (i == 0 || line[i-1] == ' ') {
+		clock = line[i+len("time="):]
+		if end := strings.IndexByte(clock, ' '); end >= 0 {
+			clock = clock[:end]
+		}
+	} else {
+		return 0, false
+	}
+
+	return parseFFmpegClock(clock)
+}
+
+// parseFFmpegClock 解析 hh:mm:ss.xx 形式的时间，开始处理前 ffmpeg 可能输出负数或 N/A
+func parseFFmpegClock(clock string) (time.Duration, bool) {
+	h, rest, ok := strings.Cut(clock, ":")
+	if !ok || strings.HasPrefix(h, "-") {
+		return 0, false
+	}
+	m, sec, ok := strings.Cut(rest, ":")
+	if !ok {
+		return 0, false
+	}
+	hours, err1 := strconv.Atoi(h)
+	minutes, err2 := strconv.Atoi(m)
+	seconds, err3 := strconv.ParseFloat(sec, 64)
+	if err1 != nil || err2 != nil || err3 != nil {
+		return 0, false
+	}
+	return time.Duration(hours)*time.Hour + time.Duration(minutes)*time.Minute +
+		time.Duration(seconds*float64(time.Second)), true
+}
+
+// parseSilenceDetect 从 ffmpeg silencedetect 滤镜的输出中读取静音区间
+//
+// 持续到结尾的静音没有 silence_end，以输入信息中的 Duration 为结束时间。
+func parseSilenceDetect(output string) []types.Silence {
+	var (
+		silences []types.Silence
+		start    time.Duration
+		open     bool
+		total    time.Duration
+	)
+	for _, line := range strings.Split(output, "\n") {
+		line = strings.TrimSpace(line)
+		if v, ok := fieldAfter(line, "silence_start:"); ok {
+			start, open = parseSeconds(v), true
+		} else if v, ok := fieldAfter(line, "silence_end:"); ok && open {
+			if end := parseSeconds(v); end > start {
+				silences = append(silences, types.Silence{Start: start, End: end})
+			}
+			open = false
+		} else if v, ok := fieldAfter(line, "Duration:"); ok && strings.HasPrefix(line, "Duration:") {
+			total, _ = parseFFmpegClock(strings.TrimSuffix(v, ","))
+		}
+	}
+	if open && total > start {
+		silences = append(silences, types.Silence{Start: start, End: total})
+	}
+	return silences
+}
+
+// fieldAfter 返回 line 中 key 之后的第一个字段
+func fieldAfter(line, key string) (string, bool) {
+	i := strings.Index(line, key)
+	if i < 0 {
+		return "", false
+	}
+	fields := strings.Fields(line[i+len(key):])
+	if len(fields) == 0 {
+		return "", false
+	}
+	return fields[0], true
+}
+
+// ffmpegSeconds 将时长格式化为 ffmpeg 参数使用的秒数
+func ffmpegSeconds(d time.Duration) string {
+	return strconv.FormatFloat(d.Seconds(), 'f', 3, 64)
+}
+
+// isProgressLine 是否为 -progress 输出的 key=value 行
+func isProgressLine(line string) bool {
+	key, _, ok := strings.Cut(line, "=")
+	return ok && key != "" && !strings.ContainsAny(key, " \t")
+}
+
+// 音频提取阶段在初始化进度中所占的范围
+const (
+	extractProgressStart = 40
+	extractProgressEnd   = 99
+)
+
+// extractProgress 将已处理的时间换算为初始化阶段的进度
+func extractProgress(pos, duration time.Duration) int {
+	if duration <= 0 || pos <= 0 {
+		return extractProgressStart
+	}
+	if pos >= duration {
+		return extractProgressEnd
+	}
+	return extractProgressStart + int(int64(extractProgressEnd-extractProgressStart)*int64(pos)/int64(duration))
+}
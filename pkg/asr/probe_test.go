@@ -0,0 +1,159 @@
+package asr
+
+import (
+	"context"
+	"os/exec"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/562589540/bcut-asr-go/pkg/types"
+)
+
+func TestParseProbeOutput(t *testing.T) {
+	data := `{
+    "streams": [
+        {"index": 0, "codec_name": "h264", "codec_type": "video", "width": 1920, "height": 1080},
+        {"index": 1, "codec_name": "aac", "profile": "LC", "codec_type": "audio",
+         "sample_rate": "48000", "channels": 2, "channel_layout": "stereo",
+         "duration": "125.500000", "bit_rate": "128000"},
+        {"index": 2, "codec_name": "opus", "codec_type": "audio",
+         "sample_rate": "16000", "channels": 1, "duration": "N/A"}
+    ],
+    "format": {
+        "format_name": "mov,mp4,m4a,3gp,3g2,mj2",
+        "duration": "125.520000",
+        "size": "10485760",
+        "bit_rate": "668300"
+    }
+}`
+	got, err := parseProbeOutput([]byte(data))
+	if err != nil {
+		t.Fatalf("parseProbeOutput() error = %v", err)
+	}
+	want := &types.MediaInfo{
+		FormatName: "mov,mp4,m4a,3gp,3g2,mj2",
+		Duration:   125520 * time.Millisecond,
+		Size:       10485760,
+		BitRate:    668300,
+		AudioStreams: []types.AudioStream{
+			{Index: 1, Codec: "aac", Profile: "LC", SampleRate: 48000, Channels: 2, ChannelLayout: "stereo", BitRate: 128000, Duration: 125500 * time.Millisecond},
+			{Index: 2, Codec: "opus", SampleRate: 16000, Channels: 1},
+		},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("parseProbeOutput() = %+v, want %+v", got, want)
+	}
+	if !got.HasAudio() {
+		t.Error("HasAudio() = false, want true")
+	}
+
+	// 容器没有时长时使用音频流的时长
+	got, err = parseProbeOutput([]byte(`{"streams":[{"codec_type":"audio","duration":"3.5"}],"format":{"format_name":"aac"}}`))
+	if err != nil {
+		t.Fatalf("parseProbeOutput() error = %v", err)
+	}
+	if got.Duration != 3500*time.Millisecond {
+		t.Errorf("Duration = %v, want 3.5s", got.Duration)
+	}
+
+	got, err = parseProbeOutput([]byte(`{"streams":[{"codec_type":"video"}],"format":{}}`))
+	if err != nil {
+		t.Fatalf("parseProbeOutput() error = %v", err)
+	}
+	if got.HasAudio() {
+		t.Error("HasAudio() = true, want false")
+	}
+
+	if _, err := parseProbeOutput([]byte("not json")); err == nil {
+		t.Error("parseProbeOutput() 无效输出应返回错误")
+	}
+}
+
+func TestParseFFmpegTime(t *testing.T) {
+	tests := []struct {
+		line string
+		want time.Duration
+		ok   bool
+	}{
+		{line: "out_time_us=1500000", want: 1500 * time.Millisecond, ok: true},
+		{line: "out_time_ms=1500000", want: 1500 * time.Millisecond, ok: true},
+		{line: "out_time=01:02:03.250000", want: time.Hour + 2*time.Minute + 3250*time.Millisecond, ok: true},
+		{line: "size=     256kB time=00:00:12.34 bitrate= 170.0kbits/s speed=24.6x", want: 12340 * time.Millisecond, ok: true},
+		{line: "out_time_us=N/A"},
+		{line: "out_time=-577014:32:22.775808"},
+		{line: "out_time=N/A"},
+		{line: "total_size=1024"},
+		{line: "[aac @ 0x1] Too many bits"},
+		{line: "runtime=00:00:01.00"},
+	}
+	for _, tt := range tests {
+		got, ok := parseFFmpegTime(tt.line)
+		if ok != tt.ok || got != tt.want {
+			t.Errorf("parseFFmpegTime(%q) = %v, %v, want %v, %v", tt.line, got, ok, tt.want, tt.ok)
+		}
+	}
+}
+
+func TestIsProgressLine(t *testing.T) {
+	for _, line := range []string{"progress=continue", "speed=24.6x", "stream_0_0_q=-1.0"} {
+		if !isProgressLine(line) {
+			t.Errorf("isProgressLine(%q) = false, want true", line)
+		}
+	}
+	for _, line := range []string{"[aac @ 0x1] Queue input is backward in time", "Error while decoding stream #0:1: Invalid data", ""} {
+		if isProgressLine(line) {
+			t.Errorf("isProgressLine(%q) = true, want false", line)
+		}
+	}
+}
+
+func TestExtractProgress(t *testing.T) {
+	duration := 100 * time.Second
+	tests := []struct {
+		pos      time.Duration
+		duration time.Duration
+		want     int
+	}{
+		{pos: 0, duration: duration, want: extractProgressStart},
+		{pos: 50 * time.Second, duration: duration, want: 69},
+		{pos: duration, duration: duration, want: extractProgressEnd},
+		{pos: 2 * duration, duration: duration, want: extractProgressEnd},
+		{pos: time.Second, duration: 0, want: extractProgressStart},
+	}
+	for _, tt := range tests {
+		if got := extractProgress(tt.pos, tt.duration); got != tt.want {
+			t.Errorf("extractProgress(%v, %v) = %d, want %d", tt.pos, tt.duration, got, tt.want)
+		}
+	}
+}
+
+func TestParseSilenceDetect(t *testing.T) {
+	output := `Input #0, mov,mp4,m4a,3gp,3g2,mj2, from 'video.mp4':
+  Duration: 00:01:10.00, start: 0.000000, bitrate: 128 kb/s
+[silencedetect @ 0x7f8] silence_start: 12.5
+[silencedetect @ 0x7f8] silence_end: 14.25 | silence_duration: 1.75
+[silencedetect @ 0x7f8] silence_start: -0.01
+[silencedetect @ 0x7f8] silence_end: 0.01 | silence_duration: 0.02
+[silencedetect @ 0x7f8] silence_start: 68
+`
+	got := parseSilenceDetect(output)
+	want := []types.Silence{
+		{Start: 12500 * time.Millisecond, End: 14250 * time.Millisecond},
+		{Start: 0, End: 10 * time.Millisecond},
+		// 到结尾仍未结束的静音以总时长结束
+		{Start: 68 * time.Second, End: 70 * time.Second},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("parseSilenceDetect() = %v, want %v", got, want)
+	}
+}
+
+func TestProbeMedia_Missing(t *testing.T) {
+	if _, err := exec.LookPath("ffprobe"); err == nil {
+		t.Skip("ffprobe 已安装")
+	}
+	if _, err := ProbeMedia(context.Background(), "video.mp4"); err == nil {
+		t.Error("ProbeMedia() ffprobe 不存在时应返回错误")
+	}
+}
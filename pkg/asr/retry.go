@@ -0,0 +1,118 @@
+package asr
+
+import (
+	"context"
+	"errors"
+	"math/rand"
+	"net/http"
+	"time"
+)
+
+// RetryPolicy 请求重试策略，作用于每一次接口调用和每一个分片上传
+type RetryPolicy struct {
+	MaxAttempts     int           // 最多尝试次数（含首次请求），小于等于 1 表示不重试
+	BaseDelay       time.Duration // 第一次重试前的等待时间，之后每次翻倍
+	MaxDelay        time.Duration // 单次等待时间上限，0 表示不限制
+	Jitter          float64       // 等待时间的随机抖动比例（0-1），避免并发请求同时重试
+	RetryableStatus []int         // 可重试的HTTP状态码
+	RetryableCodes  []int         // 可重试的接口错误码（响应中的 code）
+}
+
+// DefaultRetryPolicy 默认重试策略：网络错误、限流、5xx和状态码为 2xx 的非JSON响应最多尝试 4 次
+var DefaultRetryPolicy = RetryPolicy{
+	MaxAttempts: 4,
+	BaseDelay:   500 * time.Millisecond,
+	MaxDelay:    10 * time.Second,
+	Jitter:      0.2,
+	RetryableStatus: []int{
+		http.StatusRequestTimeout,
+		http.StatusTooManyRequests,
+		http.StatusInternalServerError,
+		http.StatusBadGateway,
+		http.StatusServiceUnavailable,
+		http.StatusGatewayTimeout,
+	},
+}
+
+// NoRetry 不重试
+var NoRetry = RetryPolicy{MaxAttempts: 1}
+
+// WithRetryPolicy 设置请求重试策略，默认为 DefaultRetryPolicy
+func WithRetryPolicy(policy RetryPolicy) Option {
+	return func(c *Client) {
+		c.retryPolicy = policy
+	}
+}
+
+// retryableStatus 状态码是否可重试
+func (p RetryPolicy) retryableStatus(status int) bool {
+	for _, s := range p.RetryableStatus {
+		if s == status {
+			return true
+		}
+	}
+	return false
+}
+
+// retryableCode 接口错误码是否可重试
+func (p RetryPolicy) retryableCode(code int) bool {
+	for _, c := range p.RetryableCodes {
+		if c == code {
+			return true
+		}
+	}
+	return false
+}
+
+// rejected 服务端是否明确拒绝了请求：不可重试的接口错误码或 4xx 状态码，如任务或资源已过期
+func (c *Client) rejected(err error) bool {
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) {
+		return false
+	}
+	if apiErr.Code != 0 {
+		return !c.retryPolicy.retryableCode(apiErr.Code)
+	}
+	return apiErr.StatusCode >= 400 && apiErr.StatusCode < 500 && !c.retryPolicy.retryableStatus(apiErr.StatusCode)
+}
+
+// backoff 第 attempt 次失败后的等待时间
+func (p RetryPolicy) backoff(attempt int) time.Duration {
+	d := p.BaseDelay
+	for i := 1; i < attempt && (p.MaxDelay <= 0 || d < p.MaxDelay); i++ {
+		d *= 2
+	}
+	if p.Jitter > 0 {
+		d = time.Duration(float64(d) * (1 - p.Jitter + 2*p.Jitter*rand.Float64()))
+	}
+	if p.MaxDelay > 0 && d > p.MaxDelay {
+		d = p.MaxDelay
+	}
+	return d
+}
+
+// retry 按策略重复执行 fn，fn 返回的 retryable 表示本次失败是否可以重试
+func (p RetryPolicy) retry(ctx context.Context, fn func(attempt int) (retryable bool, err error)) error {
+	for attempt := 1; ; attempt++ {
+		retryable, err := fn(attempt)
+		if err == nil || !retryable || attempt >= p.MaxAttempts {
+			return err
+		}
+
+		timer := time.NewTimer(p.backoff(attempt))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return err
+		case <-timer.C:
+		}
+	}
+}
+
+// retryableNetError 网络错误是否可重试，取消和超时的上下文不重试
+func retryableNetError(ctx context.Context, err error) bool {
+	if ctx.Err() != nil {
+		return false
+	}
+	return !errors.Is(err, context.Canceled) && !errors.Is(err, context.DeadlineExceeded)
+}
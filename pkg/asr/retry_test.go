@@ -0,0 +1,193 @@
+package asr
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/562589540/bcut-asr-go/pkg/types"
+)
+
+var testRetryPolicy = RetryPolicy{
+	MaxAttempts:     3,
+	BaseDelay:       time.Millisecond,
+	MaxDelay:        5 * time.Millisecond,
+	RetryableStatus: []int{http.StatusBadGateway},
+	RetryableCodes:  []int{-500},
+}
+
+func TestClient_CallRetry(t *testing.T) {
+	tests := []struct {
+		name      string
+		failures  func(w http.ResponseWriter)
+		wantCalls int
+		wantErr   bool
+	}{
+		{
+			name:      "5xx",
+			failures:  func(w http.ResponseWriter) { w.WriteHeader(http.StatusBadGateway) },
+			wantCalls: 3,
+		},
+		{
+			name:      "非JSON响应",
+			failures:  func(w http.ResponseWriter) { io.WriteString(w, "<html>502 Bad Gateway</html>") },
+			wantCalls: 3,
+		},
+		{
+			name: "可重试错误码",
+			failures: func(w http.ResponseWriter) {
+				json.NewEncoder(w).Encode(types.ASRResponse{Code: -500, Message: "busy"})
+			},
+			wantCalls: 3,
+		},
+		{
+			name: "不可重试错误码",
+			failures: func(w http.ResponseWriter) {
+				json.NewEncoder(w).Encode(types.ASRResponse{Code: -400, Message: "bad request"})
+			},
+			wantCalls: 1,
+			wantErr:   true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			calls := 0
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				calls++
+				body, _ := io.ReadAll(r.Body)
+				if !strings.Contains(string(body), "http://test.com/download") {
+					t.Errorf("attempt %d body = %q", calls, body)
+				}
+				if calls < 3 {
+					tt.failures(w)
+					return
+				}
+				json.NewEncoder(w).Encode(types.ASRResponse{
+					Code: 0,
+					Data: types.TaskCreateResponse{TaskID: "test-task"},
+				})
+			}))
+			defer server.Close()
+
+			job := NewClient(WithBaseURL(server.URL), WithRetryPolicy(testRetryPolicy)).NewJob(context.Background())
+			job.downloadURL = "http://test.com/download"
+
+			_, err := job.CreateTask()
+			if (err != nil) != tt.wantErr {
+				t.Errorf("CreateTask() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if calls != tt.wantCalls {
+				t.Errorf("calls = %d, want %d", calls, tt.wantCalls)
+			}
+		})
+	}
+}
+
+func TestClient_CallNoRetry(t *testing.T) {
+	calls := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer server.Close()
+
+	job := NewClient(WithBaseURL(server.URL), WithRetryPolicy(NoRetry)).NewJob(context.Background())
+	if _, err := job.CreateTask(); err == nil {
+		t.Error("CreateTask() should fail")
+	}
+	if calls != 1 {
+		t.Errorf("calls = %d, want 1", calls)
+	}
+}
+
+func TestClient_CallNonJSONStatus(t *testing.T) {
+	calls := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		w.WriteHeader(http.StatusUnauthorized)
+		io.WriteString(w, "<html>401 Unauthorized</html>")
+	}))
+	defer server.Close()
+
+	job := NewClient(WithBaseURL(server.URL), WithRetryPolicy(testRetryPolicy)).NewJob(context.Background())
+	_, err := job.CreateTask()
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusUnauthorized {
+		t.Errorf("CreateTask() error = %v, want APIError with status 401", err)
+	}
+	if calls != 1 {
+		t.Errorf("calls = %d, want 1", calls)
+	}
+}
+
+func TestJob_UploadPartRetry(t *testing.T) {
+	var (
+		mu    sync.Mutex
+		calls = make(map[string]int)
+	)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		calls[r.URL.Path]++
+		n := calls[r.URL.Path]
+		mu.Unlock()
+
+		data, _ := io.ReadAll(r.Body)
+		if r.URL.Path == "/b" && n == 1 {
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		}
+		w.Header().Set("Etag", string(data))
+	}))
+	defer server.Close()
+
+	resource := &types.ResourceCreateResponse{
+		PerSize:    4,
+		UploadURLs: []string{server.URL + "/a", server.URL + "/b", server.URL + "/c"},
+	}
+
+	var last int
+	job := NewClient(WithRetryPolicy(testRetryPolicy)).NewJob(context.Background()).
+		WithProgress(func(info types.ProgressInfo) { last = info.Current })
+	job.SetReader(strings.NewReader("aaaabbbbcccc"), 12, "test.mp3", "mp3")
+
+	etags, err := job.uploadParts(resource, nil, nil)
+	if err != nil {
+		t.Fatalf("uploadParts() error = %v", err)
+	}
+	if got, want := strings.Join(etags, ","), "aaaa,bbbb,cccc"; got != want {
+		t.Errorf("etags = %q, want %q", got, want)
+	}
+	for path, want := range map[string]int{"/a": 1, "/b": 2, "/c": 1} {
+		if calls[path] != want {
+			t.Errorf("calls[%s] = %d, want %d", path, calls[path], want)
+		}
+	}
+	if last != 100 {
+		t.Errorf("final progress = %d, want 100", last)
+	}
+}
+
+func TestRetryPolicy_Backoff(t *testing.T) {
+	p := RetryPolicy{BaseDelay: 100 * time.Millisecond, MaxDelay: time.Second, Jitter: 0.5}
+	for attempt := 1; attempt <= 10; attempt++ {
+		d := p.backoff(attempt)
+		if d <= 0 || d > time.Second {
+			t.Errorf("backoff(%d) = %v, want (0, 1s]", attempt, d)
+		}
+	}
+
+	p.Jitter = 0
+	for attempt, want := range map[int]time.Duration{1: 100 * time.Millisecond, 2: 200 * time.Millisecond, 3: 400 * time.Millisecond, 5: time.Second} {
+		if got := p.backoff(attempt); got != want {
+			t.Errorf("backoff(%d) = %v, want %v", attempt, got, want)
+		}
+	}
+}
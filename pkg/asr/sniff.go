@@ -0,0 +1,145 @@
+package asr
+
+import (
+	"bytes"
+	"unicode/utf8"
+)
+
+// sniffLen 识别格式时读取的文件头长度
+const sniffLen = 1024
+
+// DetectMediaFormat 根据文件头识别媒体格式
+//
+// 可以直接上传的音频返回 types.SupportedInputFormats 中的格式；其他常见的音视频容器返回
+// "mp4"、"mkv"、"ogg"、"avi"、"flv"、"ts"、"mpeg"、"asf"、"aiff"、"amr"、"caf"；无法识别时返回空字符串。
+func DetectMediaFormat(head []byte) string {
+	switch {
+	case bytes.HasPrefix(head, []byte("fLaC")):
+		return "flac"
+	case bytes.HasPrefix(head, []byte("ID3")):
+		// ID3 标签后通常是 MP3 帧
+		return "mp3"
+	case isRIFF(head, "WAVE"):
+		return "wav"
+	case isRIFF(head, "AVI "):
+		return "avi"
+	case len(head) >= 12 && string(head[4:8]) == "ftyp":
+		switch string(head[8:12]) {
+		case "M4A ", "M4B ", "M4P ":
+			return "m4a"
+		}
+		return "mp4"
+	case len(head) >= 8 && isQuickTimeAtom(string(head[4:8])):
+		return "mp4"
+	case bytes.HasPrefix(head, []byte{0x1A, 0x45, 0xDF, 0xA3}):
+		return "mkv"
+	case bytes.HasPrefix(head, []byte("OggS")):
+		return "ogg"
+	case bytes.HasPrefix(head, []byte("FLV\x01")):
+		return "flv"
+	case bytes.HasPrefix(head, []byte{0x00, 0x00, 0x01, 0xBA}):
+		return "mpeg"
+	case bytes.HasPrefix(head, []byte{0x30, 0x26, 0xB2, 0x75, 0x8E, 0x66, 0xCF, 0x11}):
+		return "asf"
+	case len(head) >= 12 && string(head[:4]) == "FORM" && (string(head[8:12]) == "AIFF" || string(head[8:12]) == "AIFC"):
+		return "aiff"
+	case bytes.HasPrefix(head, []byte("#!AMR")):
+		return "amr"
+	case bytes.HasPrefix(head, []byte("caff")):
+		return "caf"
+	case len(head) > 188 && head[0] == 0x47 && head[188] == 0x47:
+		// MPEG-TS 每 188 字节一个以 0x47 开头的包
+		return "ts"
+	case isADTS(head):
+		return "aac"
+	case isMP3Frame(head):
+		return "mp3"
+	}
+	return ""
+}
+
+func isRIFF(head []byte, form string) bool {
+	return len(head) >= 12 && string(head[:4]) == "RIFF" && string(head[8:12]) == form
+}
+
+// isQuickTimeAtom 没有 ftyp 的旧 QuickTime 文件以这些 atom 开头
+func isQuickTimeAtom(atom string) bool {
+	switch atom {
+	case "moov", "mdat", "free", "wide", "skip", "pnot":
+		return true
+	}
+	return false
+}
+
+// isADTS 12 位同步字 0xFFF，layer 为 0，采样率序号有效
+func isADTS(head []byte) bool {
+	return len(head) >= 4 &&
+		head[0] == 0xFF && head[1]&0xF6 == 0xF0 &&
+		(head[2]>>2)&0x0F < 13
+}
+
+// isMP3Frame 11 位同步字，MPEG 音频 Layer III，码率和采样率序号有效
+func isMP3Frame(head []byte) bool {
+	if len(head) < 4 || head[0] != 0xFF || head[1]&0xE0 != 0xE0 {
+		return false
+	}
+	version := (head[1] >> 3) & 0x03
+	layer := (head[1] >> 1) & 0x03
+	bitrate := head[2] >> 4
+	sampleRate := (head[2] >> 2) & 0x03
+	return version != 1 && layer == 1 && bitrate != 15 && sampleRate != 3
+}
+
+// isNonMedia 文件头是否明显不是音视频：文本或常见的文档、图片、压缩包
+func isNonMedia(head []byte) bool {
+	for _, sig := range [][]byte{
+		[]byte("%PDF"),
+		[]byte("PK\x03\x04"),
+		[]byte("\x89PNG"),
+		[]byte("GIF8"),
+		{0xFF, 0xD8, 0xFF}, // JPEG
+		{0x1F, 0x8B},       // gzip
+	} {
+		if bytes.HasPrefix(head, sig) {
+			return true
+		}
+	}
+	return isText(head)
+}
+
+// isText 是否为 UTF-8 文本，不含换行和制表符以外的控制字符
+func isText(head []byte) bool {
+	if len(head) == 0 {
+		return false
+	}
+	// 文件头可能在多字节字符中间截断
+	for i := 0; i < utf8.UTFMax-1 && len(head) > 0 && !utf8.Valid(head); i++ {
+		head = head[:len(head)-1]
+	}
+	if !utf8.Valid(head) {
+		return false
+	}
+	for _, b := range head {
+		if (b < 0x20 && b != '\t' && b != '\n' && b != '\r' && b != '\f') || b == 0x7F {
+			return false
+		}
+	}
+	return true
+}
+
+// uploadFormat 根据文件头判断可以直接上传的音频格式，hint 为扩展名或调用方指定的格式
+//
+// 返回空字符串表示需要转码，明显不是音视频时返回 ErrNotMedia。
+func uploadFormat(head []byte, hint string) (string, error) {
+	format := DetectMediaFormat(head)
+	switch {
+	case format == "mp4" && hint == "m4a":
+		// 只含音频的 MP4 也可能使用 isom、mp42 等通用品牌
+		return "m4a", nil
+	case isSupportedInputFormat(format):
+		return format, nil
+	case format == "" && isNonMedia(head):
+		return "", ErrNotMedia
+	}
+	return "", nil
+}
@@ -0,0 +1,169 @@
+package asr
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// 测试用的最小文件头
+var (
+	// MPEG-1 Layer III，128kbps，44.1kHz
+	mp3Data  = []byte{0xFF, 0xFB, 0x90, 0x64, 0x00, 0x00, 0x00, 0x00}
+	mp4Data  = []byte("\x00\x00\x00\x18ftypisom\x00\x00\x02\x00isommp41")
+	m4aData  = []byte("\x00\x00\x00\x18ftypM4A \x00\x00\x00\x00M4A mp42")
+	wavData  = []byte("RIFF\x24\x00\x00\x00WAVEfmt \x10\x00\x00\x00")
+	flacData = []byte("fLaC\x00\x00\x00\x22")
+	adtsData = []byte{0xFF, 0xF1, 0x60, 0x40, 0x10, 0x1F, 0xFC}
+)
+
+func TestDetectMediaFormat(t *testing.T) {
+	ts := make([]byte, 189)
+	ts[0], ts[188] = 0x47, 0x47
+
+	tests := []struct {
+		name string
+		head []byte
+		want string
+	}{
+		{name: "flac", head: flacData, want: "flac"},
+		{name: "adts", head: adtsData, want: "aac"},
+		{name: "adts mpeg2", head: []byte{0xFF, 0xF9, 0x50, 0x80}, want: "aac"},
+		{name: "mp3 frame", head: mp3Data, want: "mp3"},
+		{name: "mp3 mpeg2", head: []byte{0xFF, 0xF3, 0x64, 0xC4}, want: "mp3"},
+		{name: "mp3 id3", head: []byte("ID3\x04\x00\x00\x00\x00\x00\x00"), want: "mp3"},
+		{name: "wav", head: wavData, want: "wav"},
+		{name: "m4a", head: m4aData, want: "m4a"},
+		{name: "mp4", head: mp4Data, want: "mp4"},
+		{name: "quicktime", head: []byte("\x00\x00\x00\x08wide"), want: "mp4"},
+		{name: "mkv", head: []byte{0x1A, 0x45, 0xDF, 0xA3, 0x01}, want: "mkv"},
+		{name: "ogg", head: []byte("OggS\x00\x02"), want: "ogg"},
+		{name: "avi", head: []byte("RIFF\x00\x00\x00\x00AVI LIST"), want: "avi"},
+		{name: "flv", head: []byte("FLV\x01\x05"), want: "flv"},
+		{name: "ts", head: ts, want: "ts"},
+		{name: "mpeg-ps", head: []byte{0x00, 0x00, 0x01, 0xBA, 0x44}, want: "mpeg"},
+		{name: "asf", head: []byte{0x30, 0x26, 0xB2, 0x75, 0x8E, 0x66, 0xCF, 0x11, 0xA6}, want: "asf"},
+		{name: "aiff", head: []byte("FORM\x00\x00\x00\x00AIFFCOMM"), want: "aiff"},
+		{name: "amr", head: []byte("#!AMR\n"), want: "amr"},
+		// Layer II 不是 MP3
+		{name: "mp2", head: []byte{0xFF, 0xFD, 0x90, 0x64}},
+		// 无效的码率序号
+		{name: "bad bitrate", head: []byte{0xFF, 0xFB, 0xF0, 0x64}},
+		{name: "jpeg", head: []byte{0xFF, 0xD8, 0xFF, 0xE0}},
+		{name: "text", head: []byte("test data")},
+		{name: "short", head: []byte{0xFF}},
+		{name: "empty"},
+	}
+	for _, tt := range tests {
+		if got := DetectMediaFormat(tt.head); got != tt.want {
+			t.Errorf("%s: DetectMediaFormat() = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestUploadFormat(t *testing.T) {
+	tests := []struct {
+		name    string
+		head    []byte
+		hint    string
+		want    string
+		wantErr error
+	}{
+		{name: "mp3", head: mp3Data, hint: "mp3", want: "mp3"},
+		// 扩展名与内容不符时以内容为准
+		{name: "flac named mp3", head: flacData, hint: "mp3", want: "flac"},
+		{name: "mp4 named mp3", head: mp4Data, hint: "mp3"},
+		{name: "no extension", head: wavData, want: "wav"},
+		{name: "m4a generic brand", head: mp4Data, hint: "m4a", want: "m4a"},
+		{name: "mkv", head: []byte{0x1A, 0x45, 0xDF, 0xA3}, hint: "mkv"},
+		// 无法识别的二进制内容交给转码处理
+		{name: "unknown binary", head: []byte{0x00, 0x01, 0x02, 0x03}, hint: "mp3"},
+		{name: "text", head: []byte("test data"), hint: "mp3", wantErr: ErrNotMedia},
+		{name: "utf-8 text", head: []byte("字幕\n第二行\n"), wantErr: ErrNotMedia},
+		{name: "truncated utf-8", head: []byte("字幕")[:4], wantErr: ErrNotMedia},
+		{name: "png", head: []byte("\x89PNG\r\n\x1a\n"), wantErr: ErrNotMedia},
+		{name: "pdf", head: []byte("%PDF-1.7"), wantErr: ErrNotMedia},
+		{name: "zip", head: []byte("PK\x03\x04"), hint: "m4a", wantErr: ErrNotMedia},
+	}
+	for _, tt := range tests {
+		got, err := uploadFormat(tt.head, tt.hint)
+		if got != tt.want || !errors.Is(err, tt.wantErr) {
+			t.Errorf("%s: uploadFormat() = %q, %v, want %q, %v", tt.name, got, err, tt.want, tt.wantErr)
+		}
+	}
+}
+
+func TestJob_SetDataDetect(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name string, data []byte) string {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, data, 0644); err != nil {
+			t.Fatal(err)
+		}
+		return path
+	}
+
+	var transcoded []string
+	client := NewClient(WithTranscoder(TranscoderFunc(func(ctx context.Context, in TranscodeInput) (*TranscodeOutput, error) {
+		transcoded = append(transcoded, filepath.Base(in.Path))
+		return &TranscodeOutput{Audio: &fakeAudio{Reader: bytes.NewReader(adtsData)}, Size: int64(len(adtsData)), Format: "aac"}, nil
+	})))
+
+	tests := []struct {
+		path       string
+		wantName   string
+		wantFormat string
+		transcode  bool
+	}{
+		{path: write("recording", wavData), wantName: "recording.wav", wantFormat: "wav"},
+		{path: write("song.mp3", flacData), wantName: "song.flac", wantFormat: "flac"},
+		{path: write("fake.mp3", mp4Data), wantName: "fake.aac", wantFormat: "aac", transcode: true},
+		{path: write("video.mp4", m4aData), wantName: "video.m4a", wantFormat: "m4a"},
+	}
+	for _, tt := range tests {
+		transcoded = nil
+		job := client.NewJob(context.Background())
+		if err := job.SetData(tt.path); err != nil {
+			t.Fatalf("%s: SetData() error = %v", tt.path, err)
+		}
+		if job.soundName != tt.wantName || job.soundFormat != tt.wantFormat {
+			t.Errorf("%s: soundName, soundFormat = %q, %q, want %q, %q", filepath.Base(tt.path), job.soundName, job.soundFormat, tt.wantName, tt.wantFormat)
+		}
+		if (len(transcoded) > 0) != tt.transcode {
+			t.Errorf("%s: transcoded = %v, want %v", filepath.Base(tt.path), transcoded, tt.transcode)
+		}
+		job.Close()
+	}
+
+	job := client.NewJob(context.Background())
+	if err := job.SetData(write("notes.mp3", []byte("test data"))); !errors.Is(err, ErrNotMedia) {
+		t.Errorf("SetData() 文本文件 error = %v, want %v", err, ErrNotMedia)
+	}
+	if err := job.SetData(write("empty.wav", nil)); !errors.Is(err, ErrNoAudio) {
+		t.Errorf("SetData() 空文件 error = %v, want %v", err, ErrNoAudio)
+	}
+	if len(transcoded) > 0 {
+		t.Errorf("不是音视频时不应转码: %v", transcoded)
+	}
+}
+
+func TestJob_SetDataFromReaderDetect(t *testing.T) {
+	job := New(context.Background())
+	defer job.Close()
+
+	// 没有格式提示时根据内容识别
+	if err := job.SetDataFromReader(bytes.NewReader(flacData), "stdin", ""); err != nil {
+		t.Fatalf("SetDataFromReader() error = %v", err)
+	}
+	if job.soundName != "stdin.flac" || job.soundFormat != "flac" || job.soundSize != int64(len(flacData)) {
+		t.Errorf("job = %q %q %d", job.soundName, job.soundFormat, job.soundSize)
+	}
+
+	if err := job.SetDataFromReader(strings.NewReader("test data"), "stdin", "mp3"); !errors.Is(err, ErrNotMedia) {
+		t.Errorf("SetDataFromReader() 文本 error = %v, want %v", err, ErrNotMedia)
+	}
+}
@@ -0,0 +1,76 @@
+package asr
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/562589540/bcut-asr-go/pkg/types"
+)
+
+// QueryTask 查询任意任务的结果，任务未完成时返回 nil, nil
+//
+// 任务ID可以来自其他进程或之前的运行，不需要重新上传文件。
+func (c *Client) QueryTask(ctx context.Context, taskID string) (*types.ASRResult, error) {
+	return c.queryResult(ctx, taskID, nil)
+}
+
+// WaitTask 每隔 interval 查询一次任务结果，直到任务完成、失败或 ctx 取消
+func (c *Client) WaitTask(ctx context.Context, taskID string, interval time.Duration, progress types.ProgressCallback) (*types.ASRResult, error) {
+	if taskID == "" {
+		return nil, errors.New("任务ID为空")
+	}
+	if interval <= 0 {
+		interval = time.Duration(DefaultConvertOptions.PollInterval * float64(time.Second))
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		result, err := c.queryResult(ctx, taskID, progress)
+		if err != nil {
+			return nil, err
+		}
+		if result != nil {
+			return result, nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// queryResult 查询任务结果并报告任务状态
+func (c *Client) queryResult(ctx context.Context, taskID string, progress types.ProgressCallback) (*types.ASRResult, error) {
+	taskResult, err := c.queryTask(ctx, taskID)
+	if err != nil {
+		return nil, err
+	}
+
+	switch taskResult.State {
+	case types.StateStop: // 0 - 排队中
+		reportProgress(progress, types.StageProcess, 50, "排队中...")
+	case types.StateRunning: // 1 - 处理中
+		reportProgress(progress, types.StageProcess, 75, "正在识别...")
+	case types.StateError: // 3 - 失败
+		return nil, &TaskFailedError{TaskID: taskID, Remark: taskResult.Remark}
+	case types.StateComplete: // 4 - 完成
+		reportProgress(progress, types.StageComplete, 100, "识别完成")
+	}
+
+	if taskResult.State != types.StateComplete {
+		return nil, nil
+	}
+
+	var asrResult types.ASRResult
+	if err := json.Unmarshal([]byte(taskResult.Result), &asrResult); err != nil {
+		return nil, err
+	}
+
+	return &asrResult, nil
+}
@@ -0,0 +1,91 @@
+package asr
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/562589540/bcut-asr-go/pkg/types"
+)
+
+func TestClient_WaitTask(t *testing.T) {
+	queries := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/task":
+			var body map[string]string
+			json.NewDecoder(r.Body).Decode(&body)
+			if body["resource"] != "http://test.com/download" {
+				t.Errorf("resource = %v, want %v", body["resource"], "http://test.com/download")
+			}
+			json.NewEncoder(w).Encode(types.ASRResponse{
+				Code: 0,
+				Data: types.TaskCreateResponse{TaskID: "resumed-task"},
+			})
+		case "/task/result":
+			if got := r.URL.Query().Get("task_id"); got != "resumed-task" {
+				t.Errorf("task_id = %v, want %v", got, "resumed-task")
+			}
+			queries++
+			data := types.TaskResultResponse{TaskID: "resumed-task", State: types.StateRunning}
+			if queries == 3 {
+				data.State = types.StateComplete
+				data.Result = `{"utterances":[{"transcript":"resumed"}]}`
+			}
+			json.NewEncoder(w).Encode(types.ASRResponse{Code: 0, Data: data})
+		}
+	}))
+	defer server.Close()
+
+	ctx := context.Background()
+	client := NewClient(WithBaseURL(server.URL))
+
+	taskID, err := client.CreateTask(ctx, "http://test.com/download")
+	if err != nil {
+		t.Fatalf("CreateTask() error = %v", err)
+	}
+
+	result, err := client.QueryTask(ctx, taskID)
+	if err != nil || result != nil {
+		t.Fatalf("QueryTask() = %v, %v, want nil, nil", result, err)
+	}
+
+	var stages []types.ProgressStage
+	result, err = client.WaitTask(ctx, taskID, time.Millisecond, func(info types.ProgressInfo) {
+		stages = append(stages, info.Stage)
+	})
+	if err != nil {
+		t.Fatalf("WaitTask() error = %v", err)
+	}
+	if result.Utterances[0].Transcript != "resumed" {
+		t.Errorf("transcript = %v, want %v", result.Utterances[0].Transcript, "resumed")
+	}
+	if queries != 3 {
+		t.Errorf("queries = %d, want 3", queries)
+	}
+	if len(stages) != 2 || stages[1] != types.StageComplete {
+		t.Errorf("stages = %v", stages)
+	}
+}
+
+func TestClient_WaitTaskCanceled(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(types.ASRResponse{
+			Code: 0,
+			Data: types.TaskResultResponse{State: types.StateStop},
+		})
+	}))
+	defer server.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	_, err := NewClient(WithBaseURL(server.URL)).WaitTask(ctx, "test-task", time.Millisecond, nil)
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("WaitTask() error = %v, want %v", err, context.DeadlineExceeded)
+	}
+}
@@ -0,0 +1,287 @@
+package asr
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/562589540/bcut-asr-go/pkg/types"
+	"github.com/562589540/bcut-asr-go/pkg/utils"
+)
+
+// Transcoder 从不能直接上传的音视频中提取音频
+//
+// 通过 WithTranscoder 设置，默认使用 FFmpegTranscoder。
+type Transcoder interface {
+	Transcode(ctx context.Context, in TranscodeInput) (*TranscodeOutput, error)
+}
+
+// TranscoderFunc 将函数转换为 Transcoder
+type TranscoderFunc func(ctx context.Context, in TranscodeInput) (*TranscodeOutput, error)
+
+// Transcode 调用 f
+func (f TranscoderFunc) Transcode(ctx context.Context, in TranscodeInput) (*TranscodeOutput, error) {
+	return f(ctx, in)
+}
+
+// TranscodeInput 转码的输入，Reader 不为空时从 Reader 读取，否则读取 Path
+type TranscodeInput struct {
+	Path   string    // 输入文件路径
+	Reader io.Reader // 只能顺序读取的输入，如标准输入
+	// Progress 报告初始化阶段的进度（0-100），不会为 nil
+	Progress func(current int, description string)
+}
+
+// TranscodeOutput 转码得到的音频
+type TranscodeOutput struct {
+	// Audio 音频数据，由 Job 在 Close 时关闭；同时实现 io.ReaderAt 时上传支持断点续传和结果缓存
+	Audio  io.ReadCloser
+	Size   int64            // 音频大小，小于 0 表示未知，此时先写入临时文件
+	Format string           // 音频格式，必须是 types.SupportedInputFormats 之一
+	Info   *types.MediaInfo // 源媒体信息，可选
+}
+
+// FFmpegTranscoder 使用 ffmpeg 提取单声道 16kHz 的 aac 音频，是默认的 Transcoder
+//
+// 输入为文件时先用 ffprobe 读取时长，用于计算提取进度；ffprobe 不可用时不影响提取。
+type FFmpegTranscoder struct {
+	FFmpeg  string   // ffmpeg 可执行文件路径，默认为 "ffmpeg"
+	FFprobe string   // ffprobe 可执行文件路径，默认为 "ffprobe"，为 "-" 时不读取媒体信息
+	Args    []string // 额外的输出参数，添加在默认参数之后，如 []string{"-af", "loudnorm"}
+}
+
+// Transcode 提取音频到临时文件，关闭 Audio 时删除
+func (t *FFmpegTranscoder) Transcode(ctx context.Context, in TranscodeInput) (*TranscodeOutput, error) {
+	progress := in.Progress
+	if progress == nil {
+		progress = func(int, string) {}
+	}
+
+	input := in.Path
+	var info *types.MediaInfo
+	if in.Reader != nil {
+		input = "pipe:0"
+	} else if t.FFprobe != "-" {
+		progress(10, "读取媒体信息...")
+		// 读取时长用于计算提取进度，ffprobe 不可用或失败时不影响提取
+		if probed, err := probeMedia(ctx, t.command(t.FFprobe, "ffprobe"), input); err == nil {
+			if !probed.HasAudio() {
+				return nil, &TranscodeError{Err: ErrNoAudio}
+			}
+			info = probed
+		}
+	}
+	var duration time.Duration
+	if info != nil {
+		duration = info.Duration
+	}
+
+	progress(20, "准备提取音频...")
+	out, err := t.extract(ctx, []string{"-i", input}, in.Reader, duration, progress)
+	if err != nil {
+		return nil, err
+	}
+	out.Info = info
+	return out, nil
+}
+
+// TranscodeRange 提取 path 中从 start 开始、时长为 duration 的音频，用于分段识别
+func (t *FFmpegTranscoder) TranscodeRange(ctx context.Context, path string, start, duration time.Duration) (*TranscodeOutput, error) {
+	// -ss 放在 -i 之前按关键帧快速定位，重新编码时仍然精确到采样
+	input := []string{"-ss", ffmpegSeconds(start), "-i", path, "-t", ffmpegSeconds(duration)}
+	return t.extract(ctx, input, nil, duration, func(int, string) {})
+}
+
+// TranscodeSegments 提取 in.Path 中 segments 的音频并依次拼接，用于去除静音
+func (t *FFmpegTranscoder) TranscodeSegments(ctx context.Context, in TranscodeInput, segments []types.TimeRange) (*TranscodeOutput, error) {
+	if len(segments) == 0 {
+		return nil, &TranscodeError{Err: ErrNoAudio}
+	}
+	progress := in.Progress
+	if progress == nil {
+		progress = func(int, string) {}
+	}
+
+	// 只解码第一段开始到最后一段结束的范围，其中的静音由 aselect 丢弃
+	first, last := segments[0].Start, segments[len(segments)-1].End
+	var duration time.Duration
+	for _, s := range segments {
+		duration += s.End - s.Start
+	}
+	input := []string{
+		"-ss", ffmpegSeconds(first),
+		"-i", in.Path,
+		"-t", ffmpegSeconds(last - first),
+		"-af", segmentFilter(segments, first),
+	}
+	progress(20, "准备提取音频...")
+	return t.extract(ctx, input, nil, duration, progress)
+}
+
+// segmentFilter 返回只保留 segments 并重新计算时间戳的音频滤镜，offset 为输入的起始时间
+func segmentFilter(segments []types.TimeRange, offset time.Duration) string {
+	exprs := make([]string, len(segments))
+	for i, s := range segments {
+		exprs[i] = fmt.Sprintf("between(t,%s,%s)", ffmpegSeconds(s.Start-offset), ffmpegSeconds(s.End-offset))
+	}
+	return fmt.Sprintf("aselect='%s',asetpts=N/SR/TB", strings.Join(exprs, "+"))
+}
+
+// Probe 使用 ffprobe 读取媒体信息
+func (t *FFmpegTranscoder) Probe(ctx context.Context, path string) (*types.MediaInfo, error) {
+	if t.FFprobe == "-" {
+		return nil, errors.New("未启用ffprobe")
+	}
+	return probeMedia(ctx, t.command(t.FFprobe, "ffprobe"), path)
+}
+
+// extract 运行 ffmpeg 将 input 参数指定的输入转换为 aac，duration 为输出时长，用于计算进度
+func (t *FFmpegTranscoder) extract(ctx context.Context, input []string, stdin io.Reader, duration time.Duration, progress func(int, string)) (*TranscodeOutput, error) {
+	// 准备命令，-progress 将处理进度以 key=value 形式输出到 stderr
+	args := []string{
+		"-v", "warning",
+		"-nostats",
+		"-progress", "pipe:2",
+	}
+	args = append(args, input...)
+	args = append(args,
+		"-vn",
+		"-ac", "1",
+		"-acodec", "aac",
+		"-ar", "16000",
+		//"-ab", "32k",
+	)
+	args = append(args, t.Args...)
+	args = append(args, "-f", "adts", "-")
+	cmd := utils.RunCommandContext(ctx, t.command(t.FFmpeg, "ffmpeg"), args...)
+
+	// 提取的音频写入临时文件，避免整段音频驻留内存
+	tmp, err := createTempFile("aac")
+	if err != nil {
+		return nil, err
+	}
+	cmd.Stdin = stdin
+	cmd.Stdout = tmp.File
+
+	// 创建stderr管道用于进度监控
+	stderr, err := cmd.StderrPipe()
+	if err != nil {
+		tmp.Close()
+		return nil, fmt.Errorf("创建stderr管道失败: %w", err)
+	}
+
+	// 执行命令
+	progress(extractProgressStart, "开始提取音频...")
+	if err := cmd.Start(); err != nil {
+		tmp.Close()
+		return nil, &TranscodeError{Err: err}
+	}
+
+	// 监控进度，同时保留错误信息
+	var errOutput stderrTail
+	last := extractProgressStart
+	scanner := bufio.NewScanner(stderr)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if pos, ok := parseFFmpegTime(line); ok {
+			if duration <= 0 {
+				// 时长未知时无法计算百分比
+				progress(50, "音频提取中")
+			} else if current := extractProgress(pos, duration); current > last {
+				last = current
+				progress(current, "音频提取中")
+			}
+			continue
+		}
+		if isProgressLine(line) {
+			continue
+		}
+		errOutput.add(line)
+	}
+
+	if err := cmd.Wait(); err != nil {
+		tmp.Close()
+		return nil, &TranscodeError{Stderr: errOutput.String(), Err: err}
+	}
+
+	stat, err := tmp.Stat()
+	if err != nil {
+		tmp.Close()
+		return nil, err
+	}
+	if stat.Size() == 0 {
+		tmp.Close()
+		return nil, &TranscodeError{Stderr: errOutput.String(), Err: ErrNoAudio}
+	}
+	return &TranscodeOutput{Audio: tmp, Size: stat.Size(), Format: "aac"}, nil
+}
+
+// DetectSilence 使用 ffmpeg 的 silencedetect 滤镜检测音量低于 noise（dB）且持续至少 minDuration 的静音
+func (t *FFmpegTranscoder) DetectSilence(ctx context.Context, path string, noise float64, minDuration time.Duration) ([]types.Silence, error) {
+	cmd := utils.RunCommandContext(ctx, t.command(t.FFmpeg, "ffmpeg"),
+		"-hide_banner",
+		"-nostats",
+		"-i", path,
+		"-vn",
+		"-af", fmt.Sprintf("silencedetect=noise=%gdB:d=%s", noise, ffmpegSeconds(minDuration)),
+		"-f", "null",
+		"-",
+	)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		var errOutput stderrTail
+		for _, line := range strings.Split(stderr.String(), "\n") {
+			errOutput.add(line)
+		}
+		return nil, &TranscodeError{Stderr: errOutput.String(), Err: err}
+	}
+	return parseSilenceDetect(stderr.String()), nil
+}
+
+func (t *FFmpegTranscoder) command(path, name string) string {
+	if path == "" {
+		return name
+	}
+	return path
+}
+
+// tempFile 关闭时删除的临时文件
+type tempFile struct {
+	*os.File
+}
+
+func createTempFile(format string) (tempFile, error) {
+	f, err := os.CreateTemp("", "bcut-asr-*."+format)
+	if err != nil {
+		return tempFile{}, fmt.Errorf("创建临时文件失败: %w", err)
+	}
+	return tempFile{f}, nil
+}
+
+// Close 关闭并删除临时文件
+func (f tempFile) Close() error {
+	err := f.File.Close()
+	os.Remove(f.Name())
+	return err
+}
+
+// spoolTempFile 将 r 的内容写入临时文件，返回文件和大小
+func spoolTempFile(r io.Reader, format string) (tempFile, int64, error) {
+	tmp, err := createTempFile(format)
+	if err != nil {
+		return tempFile{}, 0, err
+	}
+	size, err := io.Copy(tmp.File, r)
+	if err != nil {
+		tmp.Close()
+		return tempFile{}, 0, fmt.Errorf("读取输入失败: %w", err)
+	}
+	return tmp, size, nil
+}
@@ -0,0 +1,185 @@
+package asr
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/562589540/bcut-asr-go/pkg/types"
+)
+
+// fakeAudio 记录是否被关闭的音频数据
+type fakeAudio struct {
+	*bytes.Reader
+	closed bool
+}
+
+func (a *fakeAudio) Close() error {
+	a.closed = true
+	return nil
+}
+
+// streamAudio 只能顺序读取的音频数据
+type streamAudio struct {
+	io.Reader
+}
+
+func (streamAudio) Close() error { return nil }
+
+func TestJob_Transcoder(t *testing.T) {
+	video := filepath.Join(t.TempDir(), "video.mp4")
+	if err := os.WriteFile(video, mp4Data, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	audio := &fakeAudio{Reader: bytes.NewReader([]byte("ID3audio"))}
+	info := &types.MediaInfo{Duration: time.Minute}
+	var got TranscodeInput
+	client := NewClient(WithTranscoder(TranscoderFunc(func(ctx context.Context, in TranscodeInput) (*TranscodeOutput, error) {
+		got = in
+		in.Progress(50, "转码中")
+		return &TranscodeOutput{Audio: audio, Size: int64(audio.Len()), Format: "mp3", Info: info}, nil
+	})))
+
+	var progress []int
+	job := client.NewJob(context.Background())
+	job.WithProgress(func(p types.ProgressInfo) {
+		progress = append(progress, p.Current)
+	})
+	if err := job.SetData(video); err != nil {
+		t.Fatalf("SetData() error = %v", err)
+	}
+
+	if got.Path != video || got.Reader != nil {
+		t.Errorf("TranscodeInput = %+v, want Path %q", got, video)
+	}
+	if job.soundName != "video.mp3" || job.soundFormat != "mp3" || job.soundSize != 8 || job.source == nil {
+		t.Errorf("job = %q %q %d, want video.mp3 mp3 8", job.soundName, job.soundFormat, job.soundSize)
+	}
+	if job.MediaInfo() != info {
+		t.Errorf("MediaInfo() = %v, want %v", job.MediaInfo(), info)
+	}
+	if len(progress) == 0 || progress[len(progress)-1] != 100 || !containsInt(progress, 50) {
+		t.Errorf("progress = %v", progress)
+	}
+
+	if err := job.Close(); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+	if !audio.closed {
+		t.Error("Close() 应关闭转码输出")
+	}
+	if job.MediaInfo() != nil {
+		t.Error("Close() 后 MediaInfo() 应为 nil")
+	}
+}
+
+func TestJob_TranscoderReader(t *testing.T) {
+	var input []byte
+	client := NewClient(WithTranscoder(TranscoderFunc(func(ctx context.Context, in TranscodeInput) (*TranscodeOutput, error) {
+		var err error
+		input, err = io.ReadAll(in.Reader)
+		if err != nil {
+			return nil, err
+		}
+		// 大小未知时写入临时文件
+		return &TranscodeOutput{Audio: streamAudio{strings.NewReader("aac-data")}, Size: -1, Format: "aac"}, nil
+	})))
+
+	job := client.NewJob(context.Background())
+	defer job.Close()
+	if err := job.SetDataFromReader(bytes.NewReader(mp4Data), "stdin.mkv", ""); err != nil {
+		t.Fatalf("SetDataFromReader() error = %v", err)
+	}
+	if !bytes.Equal(input, mp4Data) {
+		t.Errorf("Transcoder 读取 = %q, want %q", input, mp4Data)
+	}
+	if job.soundName != "stdin.aac" || job.soundSize != 8 || job.source == nil {
+		t.Errorf("job = %q %d, want stdin.aac 8", job.soundName, job.soundSize)
+	}
+	data := make([]byte, job.soundSize)
+	if _, err := job.source.ReadAt(data, 0); err != nil || string(data) != "aac-data" {
+		t.Errorf("ReadAt() = %q, %v", data, err)
+	}
+}
+
+func TestJob_TranscoderStream(t *testing.T) {
+	client := NewClient(WithTranscoder(TranscoderFunc(func(ctx context.Context, in TranscodeInput) (*TranscodeOutput, error) {
+		return &TranscodeOutput{Audio: streamAudio{strings.NewReader("aac-data")}, Size: 8, Format: "aac"}, nil
+	})))
+	job := client.NewJob(context.Background())
+	defer job.Close()
+	if err := job.SetDataFromReader(bytes.NewReader(mp4Data), "stdin", ""); err != nil {
+		t.Fatalf("SetDataFromReader() error = %v", err)
+	}
+	if job.source != nil || job.stream == nil || job.soundSize != 8 {
+		t.Errorf("大小已知的顺序输出应使用 SetReader")
+	}
+}
+
+func TestJob_TranscoderErrors(t *testing.T) {
+	transcodeErr := errors.New("转码服务不可用")
+	tests := []struct {
+		name string
+		out  *TranscodeOutput
+		err  error
+		want error
+	}{
+		{name: "error", err: transcodeErr, want: transcodeErr},
+		{name: "empty", out: &TranscodeOutput{Audio: &fakeAudio{Reader: bytes.NewReader(nil)}, Format: "aac"}, want: ErrNoAudio},
+		{name: "empty unknown size", out: &TranscodeOutput{Audio: &fakeAudio{Reader: bytes.NewReader(nil)}, Size: -1, Format: "aac"}, want: ErrNoAudio},
+		{name: "format", out: &TranscodeOutput{Audio: &fakeAudio{Reader: bytes.NewReader([]byte("x"))}, Size: 1, Format: "ogg"}},
+	}
+	for _, tt := range tests {
+		client := NewClient(WithTranscoder(TranscoderFunc(func(context.Context, TranscodeInput) (*TranscodeOutput, error) {
+			return tt.out, tt.err
+		})))
+		job := client.NewJob(context.Background())
+		err := job.SetDataFromReader(bytes.NewReader(mp4Data), "stdin", "")
+		if err == nil || (tt.want != nil && !errors.Is(err, tt.want)) {
+			t.Errorf("%s: SetDataFromReader() error = %v, want %v", tt.name, err, tt.want)
+		}
+		if tt.out != nil && !tt.out.Audio.(*fakeAudio).closed {
+			t.Errorf("%s: 失败时应关闭转码输出", tt.name)
+		}
+		job.Close()
+	}
+}
+
+func TestFFmpegTranscoder_Missing(t *testing.T) {
+	transcoder := &FFmpegTranscoder{
+		FFmpeg:  filepath.Join(t.TempDir(), "no-ffmpeg"),
+		FFprobe: filepath.Join(t.TempDir(), "no-ffprobe"),
+	}
+	_, err := transcoder.Transcode(context.Background(), TranscodeInput{Path: "video.mp4"})
+	var transcodeErr *TranscodeError
+	if !errors.As(err, &transcodeErr) {
+		t.Fatalf("Transcode() error = %v, want *TranscodeError", err)
+	}
+}
+
+func containsInt(list []int, v int) bool {
+	for _, n := range list {
+		if n == v {
+			return true
+		}
+	}
+	return false
+}
+
+func TestSegmentFilter(t *testing.T) {
+	got := segmentFilter([]types.TimeRange{
+		{Start: 10 * time.Second, End: 12500 * time.Millisecond},
+		{Start: 20 * time.Second, End: 30 * time.Second},
+	}, 10*time.Second)
+	want := "aselect='between(t,0.000,2.500)+between(t,10.000,20.000)',asetpts=N/SR/TB"
+	if got != want {
+		t.Errorf("segmentFilter() = %q, want %q", got, want)
+	}
+}
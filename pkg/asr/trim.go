@@ -0,0 +1,177 @@
+package asr
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/562589540/bcut-asr-go/pkg/types"
+)
+
+// TrimOptions 上传前去除长静音的选项
+//
+// 检测到的长静音（片头、中间的停顿、片尾）不上传，只上传其余部分拼接成的音频，
+// 识别结果的时间通过 types.TimeMap 映射回原音频。需要客户端的 Transcoder 实现 TrimTranscoder，否则上传完整音频。
+type TrimOptions struct {
+	MinSilence time.Duration // 持续超过该时长的静音被去除，默认为 2 秒
+	NoiseLevel float64       // 音量低于该值（dB）视为静音，默认为 -35
+	Padding    time.Duration // 去除静音时两端各保留的时长，默认为 0.3 秒，避免截掉轻声的开头和结尾
+}
+
+// withDefaults 填充未设置的选项
+func (o TrimOptions) withDefaults() TrimOptions {
+	if o.MinSilence <= 0 {
+		o.MinSilence = 2 * time.Second
+	}
+	if o.NoiseLevel == 0 {
+		o.NoiseLevel = -35
+	}
+	if o.Padding <= 0 {
+		o.Padding = 300 * time.Millisecond
+	}
+	return o
+}
+
+// TrimTranscoder 支持去除静音的 Transcoder，FFmpegTranscoder 实现了该接口
+type TrimTranscoder interface {
+	Transcoder
+	// Probe 读取媒体信息，需要其中的时长
+	Probe(ctx context.Context, path string) (*types.MediaInfo, error)
+	// DetectSilence 检测音量低于 noise（dB）且持续至少 minDuration 的静音
+	DetectSilence(ctx context.Context, path string, noise float64, minDuration time.Duration) ([]types.Silence, error)
+	// TranscodeSegments 提取 in.Path 中 segments 的音频并依次拼接，segments 按时间升序且互不重叠
+	TranscodeSegments(ctx context.Context, in TranscodeInput, segments []types.TimeRange) (*TranscodeOutput, error)
+}
+
+// keepRanges 返回时长为 total 的音频去除长静音后保留的区间，没有可去除的静音时返回 nil
+func keepRanges(total time.Duration, silences []types.Silence, opts TrimOptions) []types.TimeRange {
+	var (
+		kept    []types.TimeRange
+		pos     time.Duration
+		trimmed bool
+	)
+	for _, s := range silences {
+		if s.End-s.Start < opts.MinSilence {
+			continue
+		}
+		// 开头和结尾的静音不需要保留过渡
+		start, end := s.Start+opts.Padding, s.End-opts.Padding
+		if s.Start <= 0 {
+			start = 0
+		}
+		if s.End >= total {
+			end = total
+		}
+		if start < pos {
+			start = pos
+		}
+		if end <= start {
+			continue
+		}
+		if start > pos {
+			kept = append(kept, types.TimeRange{Start: pos, End: start})
+		}
+		pos, trimmed = end, true
+	}
+	if !trimmed {
+		return nil
+	}
+	if pos < total {
+		kept = append(kept, types.TimeRange{Start: pos, End: total})
+	}
+	return kept
+}
+
+// planTrim 检测 path 中的长静音，返回需要保留的区间；不支持、检测失败或没有可去除的静音时返回 nil
+func planTrim(ctx context.Context, transcoder Transcoder, path string, opts TrimOptions, progress types.ProgressCallback) []types.TimeRange {
+	tt, ok := transcoder.(TrimTranscoder)
+	if !ok {
+		return nil
+	}
+
+	reportProgress(progress, types.StageInit, 2, "读取媒体时长...")
+	info, err := tt.Probe(ctx, path)
+	if err != nil || !info.HasAudio() || info.Duration <= 0 {
+		return nil
+	}
+
+	reportProgress(progress, types.StageInit, 5, "检测静音...")
+	silences, err := tt.DetectSilence(ctx, path, opts.NoiseLevel, opts.MinSilence)
+	if err != nil {
+		return nil
+	}
+	kept := keepRanges(info.Duration, silences, opts)
+	if len(kept) == 0 {
+		// 全部是静音时仍然上传完整音频，由服务端返回空结果
+		return nil
+	}
+
+	var duration time.Duration
+	for _, r := range kept {
+		duration += r.End - r.Start
+	}
+	reportProgress(progress, types.StageInit, 10, fmt.Sprintf("去除静音 %v", (info.Duration-duration).Round(time.Second)))
+	return kept
+}
+
+// clipRanges 返回 ranges 与 span 的交集
+func clipRanges(ranges []types.TimeRange, span chunkSpan) []types.TimeRange {
+	var clipped []types.TimeRange
+	for _, r := range ranges {
+		start, end := r.Start, r.End
+		if start < span.Start {
+			start = span.Start
+		}
+		if end > span.End {
+			end = span.End
+		}
+		if end > start {
+			clipped = append(clipped, types.TimeRange{Start: start, End: end})
+		}
+	}
+	return clipped
+}
+
+// SetDataTrimmed 加载文件并去除其中的长静音，见 TrimOptions
+//
+// 识别结果的时间对应去除静音后的音频，需要用 TimeMap().Apply 映射回原音频。
+// 客户端的 Transcoder 未实现 TrimTranscoder 或没有可去除的静音时与 SetData 相同。
+func (j *Job) SetDataTrimmed(filePath string, opts TrimOptions) error {
+	if err := j.Close(); err != nil {
+		return err
+	}
+
+	kept := planTrim(j.ctx, j.client.transcoder, filePath, opts.withDefaults(), j.onProgress)
+	if kept == nil {
+		return j.SetData(filePath)
+	}
+	return j.setSegments(filePath, strings.TrimSuffix(filepath.Base(filePath), filepath.Ext(filePath)), kept)
+}
+
+// setSegments 提取 path 中 kept 的区间拼接后加载，name 为不含扩展名的上传文件名
+func (j *Job) setSegments(path, name string, kept []types.TimeRange) error {
+	tt := j.client.transcoder.(TrimTranscoder)
+	out, err := tt.TranscodeSegments(j.ctx, TranscodeInput{
+		Path: path,
+		Progress: func(current int, description string) {
+			j.reportProgress(types.StageInit, current, description)
+		},
+	}, kept)
+	if err != nil {
+		return err
+	}
+	if err := j.setTranscoded(out, name); err != nil {
+		return err
+	}
+	j.timeMap = types.NewTimeMap(kept)
+
+	j.reportProgress(types.StageInit, 100, "音频提取完成")
+	return nil
+}
+
+// TimeMap 返回去除静音后的音频到原音频的时间映射，未去除静音时为 nil
+func (j *Job) TimeMap() types.TimeMap {
+	return j.timeMap
+}
@@ -0,0 +1,199 @@
+package asr
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/562589540/bcut-asr-go/pkg/types"
+)
+
+func TestKeepRanges(t *testing.T) {
+	opts := TrimOptions{}.withDefaults()
+	tests := []struct {
+		name     string
+		silences []types.Silence
+		want     []types.TimeRange
+	}{
+		{
+			name: "no long silence",
+			silences: []types.Silence{
+				{Start: 5 * time.Second, End: 6 * time.Second},
+			},
+			want: nil,
+		},
+		{
+			name: "intro, pause and outro",
+			silences: []types.Silence{
+				{Start: 0, End: 10 * time.Second},
+				{Start: 20 * time.Second, End: 21 * time.Second},
+				{Start: 30 * time.Second, End: 40 * time.Second},
+				{Start: 55 * time.Second, End: 60 * time.Second},
+			},
+			want: []types.TimeRange{
+				{Start: 9700 * time.Millisecond, End: 30300 * time.Millisecond},
+				{Start: 39700 * time.Millisecond, End: 55300 * time.Millisecond},
+			},
+		},
+		{
+			name: "all silence",
+			silences: []types.Silence{
+				{Start: 0, End: time.Minute},
+			},
+			want: nil,
+		},
+	}
+	for _, tt := range tests {
+		if got := keepRanges(time.Minute, tt.silences, opts); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s: keepRanges() = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestClipRanges(t *testing.T) {
+	ranges := []types.TimeRange{
+		{Start: 0, End: 10 * time.Second},
+		{Start: 20 * time.Second, End: 40 * time.Second},
+	}
+	got := clipRanges(ranges, chunkSpan{Start: 5 * time.Second, End: 30 * time.Second})
+	want := []types.TimeRange{
+		{Start: 5 * time.Second, End: 10 * time.Second},
+		{Start: 20 * time.Second, End: 30 * time.Second},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("clipRanges() = %v, want %v", got, want)
+	}
+	if got := clipRanges(ranges, chunkSpan{Start: 10 * time.Second, End: 20 * time.Second}); got != nil {
+		t.Errorf("clipRanges() = %v, want nil", got)
+	}
+}
+
+func TestTranscribe_Trim(t *testing.T) {
+	server := newChunkServer(t)
+	defer server.Close()
+
+	input := filepath.Join(t.TempDir(), "video.mp4")
+	if err := os.WriteFile(input, mp4Data, 0644); err != nil {
+		t.Fatal(err)
+	}
+	transcoder := &fakeChunkTranscoder{
+		duration: time.Minute,
+		silences: []types.Silence{{Start: 0, End: 10 * time.Second}},
+	}
+
+	result, err := Transcribe(context.Background(), input, ConvertOptions{
+		PollInterval: 0.001,
+		Client:       NewClient(WithBaseURL(server.URL), WithTranscoder(transcoder)),
+		Trim:         &TrimOptions{},
+	})
+	if err != nil {
+		t.Fatalf("Transcribe() error = %v", err)
+	}
+
+	wantSegments := [][]types.TimeRange{{{Start: 9700 * time.Millisecond, End: time.Minute}}}
+	if !reflect.DeepEqual(transcoder.segments, wantSegments) {
+		t.Errorf("TranscodeSegments() = %v, want %v", transcoder.segments, wantSegments)
+	}
+	// 上传音频中 5-6 秒的句子在原音频中为 14.7-15.7 秒
+	want := []types.Utterance{{StartTime: 14700, EndTime: 15700, Transcript: "video.aac"}}
+	if !reflect.DeepEqual(result.Utterances, want) {
+		t.Errorf("Transcribe() = %+v, want %+v", result.Utterances, want)
+	}
+}
+
+func TestTranscribe_TrimChunks(t *testing.T) {
+	server := newChunkServer(t)
+	defer server.Close()
+
+	input := filepath.Join(t.TempDir(), "video.mp4")
+	if err := os.WriteFile(input, mp4Data, 0644); err != nil {
+		t.Fatal(err)
+	}
+	transcoder := &fakeChunkTranscoder{
+		duration: 70 * time.Minute,
+		silences: []types.Silence{{Start: 29 * time.Minute, End: 31 * time.Minute}},
+	}
+
+	result, err := Transcribe(context.Background(), input, ConvertOptions{
+		PollInterval: 0.001,
+		Client:       NewClient(WithBaseURL(server.URL), WithTranscoder(transcoder)),
+		Chunk:        ChunkOptions{Duration: 30 * time.Minute},
+		Trim:         &TrimOptions{},
+	})
+	if err != nil {
+		t.Fatalf("Transcribe() error = %v", err)
+	}
+	if len(transcoder.ranges) != 0 || len(transcoder.segments) != 3 {
+		t.Errorf("去除静音时应按区间提取各段: ranges %v, segments %v", transcoder.ranges, transcoder.segments)
+	}
+
+	// 第二段开头的静音被去除，句子的时间映射回原音频
+	want := []types.Utterance{
+		{StartTime: 5000, EndTime: 6000, Transcript: "video_001.aac"},
+		{StartTime: 1864700, EndTime: 1865700, Transcript: "video_002.aac"},
+		{StartTime: 3604000, EndTime: 3605000, Transcript: "video_003.aac"},
+	}
+	if !reflect.DeepEqual(result.Utterances, want) {
+		t.Errorf("Transcribe() = %+v, want %+v", result.Utterances, want)
+	}
+}
+
+func TestJob_SetDataTrimmed(t *testing.T) {
+	input := filepath.Join(t.TempDir(), "video.mp4")
+	if err := os.WriteFile(input, mp4Data, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	transcoder := &fakeChunkTranscoder{
+		duration: time.Minute,
+		silences: []types.Silence{{Start: 50 * time.Second, End: time.Minute}},
+	}
+	job := NewClient(WithTranscoder(transcoder)).NewJob(context.Background())
+	defer job.Close()
+	if err := job.SetDataTrimmed(input, TrimOptions{}); err != nil {
+		t.Fatalf("SetDataTrimmed() error = %v", err)
+	}
+	want := types.TimeMap{{Start: 0, Source: 0, Length: 50300}}
+	if !reflect.DeepEqual(job.TimeMap(), want) {
+		t.Errorf("TimeMap() = %+v, want %+v", job.TimeMap(), want)
+	}
+
+	// 没有长静音时与 SetData 相同
+	transcoder.silences = nil
+	if err := job.SetDataTrimmed(input, TrimOptions{}); err != nil {
+		t.Fatalf("SetDataTrimmed() error = %v", err)
+	}
+	if job.TimeMap() != nil || len(transcoder.segments) != 1 {
+		t.Errorf("TimeMap() = %+v, segments %v", job.TimeMap(), transcoder.segments)
+	}
+}
+
+func TestJob_SetDataTrimmedClose(t *testing.T) {
+	input := filepath.Join(t.TempDir(), "video.mp4")
+	if err := os.WriteFile(input, mp4Data, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	transcoder := &fakeChunkTranscoder{
+		duration: time.Minute,
+		silences: []types.Silence{{Start: 0, End: 10 * time.Second}},
+	}
+	job := NewClient(WithTranscoder(transcoder)).NewJob(context.Background())
+	for i := 0; i < 2; i++ {
+		if err := job.SetDataTrimmed(input, TrimOptions{}); err != nil {
+			t.Fatalf("SetDataTrimmed() error = %v", err)
+		}
+	}
+	if len(transcoder.outputs) != 2 || !transcoder.outputs[0].closed {
+		t.Fatalf("再次加载前应关闭上一次的音频: %d 个输出", len(transcoder.outputs))
+	}
+	if err := job.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if !transcoder.outputs[1].closed {
+		t.Error("Close() 应关闭当前的音频")
+	}
+}
@@ -0,0 +1,252 @@
+package asr
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"sync"
+
+	"github.com/562589540/bcut-asr-go/pkg/types"
+)
+
+// uploadPart 待上传的分片
+type uploadPart struct {
+	index   int
+	newBody func() io.Reader // 每次尝试返回从分片开头读取的 Reader
+	size    int64
+	release func() // 上传结束后归还缓冲区
+}
+
+// uploadParts 按客户端设置的并发数上传所有分片，返回按分片顺序排列的 etag
+//
+// done 中已有 etag 的分片跳过上传，每个分片完成后串行调用 onPart（可以为空）。
+func (j *Job) uploadParts(resource *types.ResourceCreateResponse, done []string, onPart func(part int, etag string) error) ([]string, error) {
+	totalParts := len(resource.UploadURLs)
+	perSize := int64(resource.PerSize)
+	if perSize <= 0 && totalParts > 1 {
+		return nil, fmt.Errorf("invalid part size: %d", resource.PerSize)
+	}
+	// 分片数必须与音频大小一致，否则多出的分片长度为负，或者末尾的数据不会上传
+	if totalParts == 0 || totalParts > 1 &&
+		(int64(totalParts-1)*perSize >= j.soundSize || int64(totalParts)*perSize < j.soundSize) {
+		return nil, fmt.Errorf("invalid part count: %d parts of %d bytes for %d bytes", totalParts, resource.PerSize, j.soundSize)
+	}
+
+	workers := j.client.uploadConcurrency
+	if workers > totalParts {
+		workers = totalParts
+	}
+	if workers < 1 {
+		workers = 1
+	}
+
+	ctx, cancel := context.WithCancel(j.ctx)
+	defer cancel()
+
+	var (
+		etags    = make([]string, totalParts)
+		progress = newUploadProgress(j, j.soundSize, totalParts)
+		partMu   sync.Mutex
+		wg       sync.WaitGroup
+		errOnce  sync.Once
+		firstErr error
+	)
+	copy(etags, done)
+	fail := func(err error) {
+		errOnce.Do(func() {
+			firstErr = err
+			cancel()
+		})
+	}
+
+	parts := make(chan uploadPart)
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for p := range parts {
+				// 重传时回退上一次尝试已统计的字节数
+				var last *countingReader
+				body := func() io.Reader {
+					if last != nil {
+						progress.add(-last.n)
+					}
+					last = &countingReader{r: p.newBody(), progress: progress}
+					return last
+				}
+				etag, err := j.client.uploadPart(ctx, resource.UploadURLs[p.index], body, p.size, p.index)
+				if p.release != nil {
+					p.release()
+				}
+				if err != nil {
+					fail(err)
+					continue
+				}
+				etags[p.index] = etag
+				if onPart != nil {
+					partMu.Lock()
+					err = onPart(p.index, etag)
+					partMu.Unlock()
+					if err != nil {
+						fail(err)
+						continue
+					}
+				}
+				progress.partDone()
+			}
+		}()
+	}
+
+	// 顺序读取时每个并发上传占用一个分片缓冲区
+	var buffers chan []byte
+	if j.source == nil {
+		bufSize := j.soundSize
+		if totalParts > 1 && perSize < bufSize {
+			bufSize = perSize
+		}
+		buffers = make(chan []byte, workers)
+		for w := 0; w < workers; w++ {
+			buffers <- make([]byte, bufSize)
+		}
+	}
+
+produce:
+	for i := 0; i < totalParts; i++ {
+		start := int64(i) * perSize
+		end := start + perSize
+		if end > j.soundSize || totalParts == 1 {
+			end = j.soundSize
+		}
+
+		// 已完成的分片，顺序读取时跳过对应数据
+		if etags[i] != "" {
+			if j.source == nil {
+				if _, err := io.CopyN(io.Discard, j.stream, end-start); err != nil {
+					fail(fmt.Errorf("read part %d failed: %w", i, err))
+					break produce
+				}
+			}
+			progress.add(end - start)
+			progress.partDone()
+			continue
+		}
+
+		p := uploadPart{index: i, size: end - start}
+		if j.source != nil {
+			p.newBody = func() io.Reader { return io.NewSectionReader(j.source, start, end-start) }
+		} else {
+			var buf []byte
+			select {
+			case buf = <-buffers:
+			case <-ctx.Done():
+				break produce
+			}
+			data := buf[:end-start]
+			if _, err := io.ReadFull(j.stream, data); err != nil {
+				fail(fmt.Errorf("read part %d failed: %w", i, err))
+				break produce
+			}
+			p.newBody = func() io.Reader { return bytes.NewReader(data) }
+			p.release = func() { buffers <- buf }
+		}
+
+		select {
+		case parts <- p:
+		case <-ctx.Done():
+			break produce
+		}
+	}
+	close(parts)
+	wg.Wait()
+
+	if firstErr != nil {
+		return nil, firstErr
+	}
+	if err := j.ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	// 最后一次性报告100%
+	j.reportProgress(types.StageUpload, 100, "上传完成")
+	return etags, nil
+}
+
+// uploadProgress 汇总所有分片的已上传字节数，串行调用进度回调
+type uploadProgress struct {
+	mu         sync.Mutex
+	job        *Job
+	total      int64
+	uploaded   int64
+	totalParts int
+	doneParts  int
+	last       int
+}
+
+func newUploadProgress(job *Job, total int64, totalParts int) *uploadProgress {
+	return &uploadProgress{job: job, total: total, totalParts: totalParts, last: -1}
+}
+
+// add 记录已上传的字节数，n 为负数时表示重传前回退
+func (p *uploadProgress) add(n int64) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.uploaded += n
+	p.report()
+}
+
+// partDone 记录完成的分片数
+func (p *uploadProgress) partDone() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.doneParts++
+	p.last = -1
+	p.report()
+}
+
+// report 上传进度按字节计算 (0-90%)，百分比变化时才回调
+func (p *uploadProgress) report() {
+	current := 90
+	if p.total > 0 {
+		current = int(p.uploaded * 90 / p.total)
+	}
+	if current == p.last {
+		return
+	}
+	p.last = current
+	p.job.reportProgress(
+		types.StageUpload,
+		current,
+		fmt.Sprintf("正在上传分片 %d/%d (%s/%s)", p.doneParts, p.totalParts, formatBytes(p.uploaded), formatBytes(p.total)),
+	)
+}
+
+// countingReader 统计读取的字节数
+type countingReader struct {
+	r        io.Reader
+	n        int64
+	progress *uploadProgress
+}
+
+func (c *countingReader) Read(b []byte) (int, error) {
+	n, err := c.r.Read(b)
+	if n > 0 {
+		c.n += int64(n)
+		c.progress.add(int64(n))
+	}
+	return n, err
+}
+
+// formatBytes 格式化字节数
+func formatBytes(n int64) string {
+	switch {
+	case n >= 1<<30:
+		return fmt.Sprintf("%.1fGB", float64(n)/(1<<30))
+	case n >= 1<<20:
+		return fmt.Sprintf("%.1fMB", float64(n)/(1<<20))
+	case n >= 1<<10:
+		return fmt.Sprintf("%.1fKB", float64(n)/(1<<10))
+	default:
+		return fmt.Sprintf("%dB", n)
+	}
+}
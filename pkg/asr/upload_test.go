@@ -0,0 +1,157 @@
+package asr
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/562589540/bcut-asr-go/pkg/types"
+)
+
+func TestJob_UploadPartsConcurrent(t *testing.T) {
+	var inFlight, maxInFlight int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := atomic.AddInt32(&inFlight, 1)
+		defer atomic.AddInt32(&inFlight, -1)
+		for {
+			m := atomic.LoadInt32(&maxInFlight)
+			if n <= m || atomic.CompareAndSwapInt32(&maxInFlight, m, n) {
+				break
+			}
+		}
+		time.Sleep(20 * time.Millisecond)
+
+		if r.ContentLength < 0 {
+			t.Errorf("part %s sent without Content-Length", r.URL.Path)
+		}
+		data, _ := io.ReadAll(r.Body)
+		w.Header().Set("Etag", string(data))
+	}))
+	defer server.Close()
+
+	data := "aaaabbbbccccddddeeeeff"
+	resource := &types.ResourceCreateResponse{PerSize: 4}
+	for i := 0; i*4 < len(data); i++ {
+		resource.UploadURLs = append(resource.UploadURLs, server.URL+"/"+string(rune('a'+i)))
+	}
+	want := "aaaa,bbbb,cccc,dddd,eeee,ff"
+
+	tests := []struct {
+		name string
+		set  func(j *Job)
+	}{
+		{
+			name: "ReaderAt",
+			set: func(j *Job) {
+				j.SetReaderAt(strings.NewReader(data), int64(len(data)), "test.mp3", "mp3")
+			},
+		},
+		{
+			name: "Reader",
+			set: func(j *Job) {
+				j.SetReader(strings.NewReader(data), int64(len(data)), "test.mp3", "mp3")
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			atomic.StoreInt32(&maxInFlight, 0)
+
+			var (
+				mu      sync.Mutex
+				reports []types.ProgressInfo
+			)
+			job := NewClient(WithUploadConcurrency(3)).NewJob(context.Background()).
+				WithProgress(func(info types.ProgressInfo) {
+					mu.Lock()
+					reports = append(reports, info)
+					mu.Unlock()
+				})
+			tt.set(job)
+
+			etags, err := job.uploadParts(resource, nil, nil)
+			if err != nil {
+				t.Fatalf("uploadParts() error = %v", err)
+			}
+			if got := strings.Join(etags, ","); got != want {
+				t.Errorf("etags = %q, want %q", got, want)
+			}
+			if got := atomic.LoadInt32(&maxInFlight); got != 3 {
+				t.Errorf("max concurrent parts = %d, want %d", got, 3)
+			}
+
+			last := -1
+			for _, info := range reports {
+				if info.Current < last {
+					t.Errorf("progress went backwards: %d -> %d", last, info.Current)
+				}
+				last = info.Current
+			}
+			if last != 100 {
+				t.Errorf("final progress = %d, want 100", last)
+			}
+		})
+	}
+}
+
+func TestJob_UploadPartsFailure(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/b" {
+			w.Header().Set("Etag", "ok")
+		}
+	}))
+	defer server.Close()
+
+	resource := &types.ResourceCreateResponse{
+		PerSize:    4,
+		UploadURLs: []string{server.URL + "/a", server.URL + "/b", server.URL + "/c"},
+	}
+	job := NewClient(WithUploadConcurrency(2)).NewJob(context.Background())
+	job.SetReader(strings.NewReader("aaaabbbbcccc"), 12, "test.mp3", "mp3")
+
+	if _, err := job.uploadParts(resource, nil, nil); err == nil {
+		t.Error("uploadParts() should fail when a part has no etag")
+	}
+}
+
+func TestJob_UploadPartsCountMismatch(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Errorf("unexpected part upload %s", r.URL.Path)
+		w.Header().Set("Etag", "ok")
+	}))
+	defer server.Close()
+
+	tests := []struct {
+		name string
+		data string
+		urls int
+	}{
+		{name: "too many parts", data: "aaaab", urls: 3},
+		{name: "too few parts", data: "aaaabbbbc", urls: 2},
+		{name: "no parts", data: "aaaab", urls: 0},
+	}
+	for _, tt := range tests {
+		resource := &types.ResourceCreateResponse{PerSize: 4}
+		for i := 0; i < tt.urls; i++ {
+			resource.UploadURLs = append(resource.UploadURLs, server.URL+"/"+string(rune('a'+i)))
+		}
+		size := int64(len(tt.data))
+
+		job := NewClient().NewJob(context.Background())
+		job.SetReaderAt(strings.NewReader(tt.data), size, "test.mp3", "mp3")
+		if _, err := job.uploadParts(resource, nil, nil); err == nil {
+			t.Errorf("%s: uploadParts() with ReaderAt should fail", tt.name)
+		}
+		job.SetReader(strings.NewReader(tt.data), size, "test.mp3", "mp3")
+		if _, err := job.uploadParts(resource, nil, nil); err == nil {
+			t.Errorf("%s: uploadParts() with Reader should fail", tt.name)
+		}
+	}
+}
@@ -0,0 +1,289 @@
+package types
+
+import (
+	"bufio"
+	"fmt"
+	"image/color"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// ASSStyle ASS 字幕样式，对应 [V4+ Styles] 中的一行
+type ASSStyle struct {
+	Name           string      // 样式名
+	FontName       string      // 字体
+	FontSize       int         // 字号，相对于 PlayResY
+	PrimaryColor   color.NRGBA // 文字颜色，卡拉OK模式下为已唱部分的颜色
+	SecondaryColor color.NRGBA // 卡拉OK模式下未唱部分的颜色
+	OutlineColor   color.NRGBA // 描边颜色
+	BackColor      color.NRGBA // 阴影颜色
+	Bold           bool
+	Italic         bool
+	Outline        float64 // 描边宽度
+	Shadow         float64 // 阴影距离
+	Alignment      int     // 对齐方式，小键盘布局，2 为底部居中
+	MarginL        int     // 左边距
+	MarginR        int     // 右边距
+	MarginV        int     // 垂直边距
+}
+
+// DefaultASSStyle 默认样式：白字黑边，底部居中
+func DefaultASSStyle() ASSStyle {
+	return ASSStyle{
+		Name:           "Default",
+		FontName:       "Arial",
+		FontSize:       60,
+		PrimaryColor:   color.NRGBA{R: 0xff, G: 0xff, B: 0xff, A: 0xff},
+		SecondaryColor: color.NRGBA{R: 0xff, G: 0xff, A: 0xff},
+		OutlineColor:   color.NRGBA{A: 0xff},
+		BackColor:      color.NRGBA{A: 0x80},
+		Outline:        2,
+		Shadow:         1,
+		Alignment:      2,
+		MarginL:        20,
+		MarginR:        20,
+		MarginV:        40,
+	}
+}
+
+// ASSOptions ASS 输出选项
+type ASSOptions struct {
+	Title    string    // 标题
+	PlayResX int       // 画面宽度，默认 1920
+	PlayResY int       // 画面高度，默认 1080
+	Style    *ASSStyle // 字幕样式，为 nil 时使用 DefaultASSStyle
+	Karaoke  bool      // 按词级时间戳输出 \k 卡拉OK标签
+}
+
+// ToASS 将识别结果转换为ASS格式
+func (r *ASRResult) ToASS() string {
+	return r.ToASSWithOptions(ASSOptions{})
+}
+
+// ToASSWithOptions 按选项将识别结果转换为ASS格式
+func (r *ASRResult) ToASSWithOptions(opts ASSOptions) string {
+	var b strings.Builder
+	r.WriteASS(&b, opts)
+	return b.String()
+}
+
+// WriteASS 将ASS格式的识别结果写入 w
+func (r *ASRResult) WriteASS(w io.Writer, opts ASSOptions) error {
+	if opts.PlayResX <= 0 {
+		opts.PlayResX = 1920
+	}
+	if opts.PlayResY <= 0 {
+		opts.PlayResY = 1080
+	}
+	style := DefaultASSStyle()
+	if opts.Style != nil {
+		style = *opts.Style
+	}
+	if style.Name == "" {
+		style.Name = "Default"
+	}
+
+	b := bufio.NewWriter(w)
+	b.WriteString("[Script Info]\n")
+	if opts.Title != "" {
+		fmt.Fprintf(b, "Title: %s\n", opts.Title)
+	}
+	b.WriteString("ScriptType: v4.00+\n")
+	b.WriteString("WrapStyle: 0\n")
+	b.WriteString("ScaledBorderAndShadow: yes\n")
+	fmt.Fprintf(b, "PlayResX: %d\nPlayResY: %d\n\n", opts.PlayResX, opts.PlayResY)
+
+	b.WriteString("[V4+ Styles]\n")
+	b.WriteString("Format: Name, Fontname, Fontsize, PrimaryColour, SecondaryColour, OutlineColour, BackColour, " +
+		"Bold, Italic, Underline, StrikeOut, ScaleX, ScaleY, Spacing, Angle, BorderStyle, Outline, Shadow, " +
+		"Alignment, MarginL, MarginR, MarginV, Encoding\n")
+	fmt.Fprintf(b, "Style: %s,%s,%d,%s,%s,%s,%s,%d,%d,0,0,100,100,0,0,1,%g,%g,%d,%d,%d,%d,1\n\n",
+		style.Name, style.FontName, style.FontSize,
+		assColor(style.PrimaryColor), assColor(style.SecondaryColor),
+		assColor(style.OutlineColor), assColor(style.BackColor),
+		assBool(style.Bold), assBool(style.Italic),
+		style.Outline, style.Shadow, style.Alignment,
+		style.MarginL, style.MarginR, style.MarginV)
+
+	b.WriteString("[Events]\n")
+	b.WriteString("Format: Layer, Start, End, Style, Name, MarginL, MarginR, MarginV, Effect, Text\n")
+	for _, u := range r.Utterances {
+		fmt.Fprintf(b, "Dialogue: 0,%s,%s,%s,,0,0,0,,%s\n",
+			formatASSTimestamp(u.StartTime),
+			formatASSTimestamp(u.EndTime),
+			style.Name,
+			assText(u, opts))
+	}
+	return b.Flush()
+}
+
+// assText 生成字幕文本，卡拉OK模式下每个词前插入 \k 标签，词间停顿用空的 \k 标签占位
+func assText(u Utterance, opts ASSOptions) string {
+	if !opts.Karaoke || len(u.Words) == 0 {
+		return escapeASS(u.Transcript)
+	}
+
+	var (
+		b    strings.Builder
+		prev string
+	)
+	// 按厘秒计算时长，避免累计误差
+	cursor := u.StartTime / 10
+	for _, w := range u.Words {
+		if needSpace(prev, w.Label) {
+			b.WriteByte(' ')
+		}
+		start, end := w.StartTime/10, w.EndTime/10
+		if start > cursor {
+			fmt.Fprintf(&b, "{\\k%d}", start-cursor)
+			cursor = start
+		}
+		if end < cursor {
+			end = cursor
+		}
+		fmt.Fprintf(&b, "{\\k%d}%s", end-cursor, escapeASS(w.Label))
+		cursor = end
+		prev = w.Label
+	}
+	return b.String()
+}
+
+// escapeASS 将花括号替换为全角字符，避免被解析为样式标签；换行转换为 \N
+//
+// ASS 没有花括号的转义写法，libass 和 VSFilter 会原样显示反斜杠。
+func escapeASS(s string) string {
+	return strings.NewReplacer("{", "｛", "}", "｝", "\r\n", "\\N", "\n", "\\N").Replace(s)
+}
+
+// assColor 转换为 &HAABBGGRR，ASS 的透明度 00 为不透明
+func assColor(c color.NRGBA) string {
+	return fmt.Sprintf("&H%02X%02X%02X%02X", 0xff-c.A, c.B, c.G, c.R)
+}
+
+func assBool(v bool) int {
+	if v {
+		return -1
+	}
+	return 0
+}
+
+func formatASSTimestamp(ts int64) string {
+	return fmt.Sprintf("%d:%02d:%02d.%02d",
+		ts/3600000, (ts/60000)%60, (ts/1000)%60, (ts%1000)/10)
+}
+
+// ParseASS 解析ASS/SSA字幕的 [Events]，\k 卡拉OK标签还原为词级时间戳，其他样式标签被忽略
+func ParseASS(r io.Reader) (*ASRResult, error) {
+	lines, err := readLines(r)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &ASRResult{}
+	inEvents := false
+	fields := []string{"layer", "start", "end", "style", "name", "marginl", "marginr", "marginv", "effect", "text"}
+	for i, line := range lines {
+		line = strings.TrimSpace(line)
+		if strings.HasPrefix(line, "[") {
+			inEvents = strings.EqualFold(line, "[Events]")
+			continue
+		}
+		if !inEvents {
+			continue
+		}
+
+		key, value, ok := strings.Cut(line, ":")
+		if !ok {
+			continue
+		}
+		switch strings.TrimSpace(key) {
+		case "Format":
+			fields = fields[:0]
+			for _, f := range strings.Split(value, ",") {
+				fields = append(fields, strings.ToLower(strings.TrimSpace(f)))
+			}
+		case "Dialogue":
+			values := strings.SplitN(strings.TrimLeft(value, " "), ",", len(fields))
+			if len(values) != len(fields) {
+				return nil, fmt.Errorf("第 %d 行: 字段数量不足", i+1)
+			}
+			var (
+				start, end int64
+				text       string
+			)
+			for k, f := range fields {
+				var err error
+				switch f {
+				case "start":
+					start, err = parseClock(values[k])
+				case "end":
+					end, err = parseClock(values[k])
+				case "text":
+					text = values[k]
+				}
+				if err != nil {
+					return nil, fmt.Errorf("第 %d 行: %w", i+1, err)
+				}
+			}
+			transcript, words := parseASSText(text, start)
+			result.Utterances = append(result.Utterances, Utterance{
+				StartTime:  start,
+				EndTime:    end,
+				Transcript: transcript,
+				Words:      words,
+			})
+		}
+	}
+	return result, nil
+}
+
+// assKaraoke 匹配样式块中的 \k、\K、\kf、\ko 标签，时长单位为厘秒
+var assKaraoke = regexp.MustCompile(`\\(?:kf|ko|k|K)(\d+)`)
+
+// parseASSText 去掉样式标签，\k 标签之后的文本为一个词
+func parseASSText(text string, start int64) (string, []Words) {
+	var (
+		transcript strings.Builder
+		segments   = []timedSegment{{start: start, end: -1}}
+		karaoke    bool
+		cursor     = start
+	)
+	appendText := func(s string) {
+		segments[len(segments)-1].text += s
+		transcript.WriteString(s)
+	}
+	for i := 0; i < len(text); i++ {
+		switch {
+		case strings.HasPrefix(text[i:], `\N`), strings.HasPrefix(text[i:], `\n`):
+			appendText("\n")
+			i++
+		case strings.HasPrefix(text[i:], `\h`):
+			appendText(" ")
+			i++
+		case text[i] == '{':
+			j := strings.IndexByte(text[i:], '}')
+			if j < 0 {
+				appendText(text[i:])
+				i = len(text)
+				break
+			}
+			for _, m := range assKaraoke.FindAllStringSubmatch(text[i:i+j], -1) {
+				cs, _ := strconv.ParseInt(m[1], 10, 64)
+				segments = append(segments, timedSegment{start: cursor, end: cursor + cs*10})
+				cursor += cs * 10
+				karaoke = true
+			}
+			i += j
+		default:
+			appendText(text[i : i+1])
+		}
+	}
+
+	if !karaoke {
+		return transcript.String(), nil
+	}
+	// \k 之前的文本没有时间信息
+	return transcript.String(), segmentsToWords(segments[1:], cursor)
+}
@@ -0,0 +1,66 @@
+package types
+
+import (
+	"image/color"
+	"strings"
+	"testing"
+)
+
+func TestASRResult_ToASS(t *testing.T) {
+	result := &ASRResult{
+		Utterances: []Utterance{
+			{
+				StartTime:  1000,
+				EndTime:    2500,
+				Transcript: "hello {world}",
+				Words: []Words{
+					{Label: "hello", StartTime: 1000, EndTime: 1500},
+					{Label: "world", StartTime: 1700, EndTime: 2500},
+				},
+			},
+			{
+				StartTime:  3723004,
+				EndTime:    3724000,
+				Transcript: "测试字幕",
+			},
+		},
+	}
+
+	got := result.ToASS()
+	for _, want := range []string{
+		"[Script Info]\n",
+		"PlayResX: 1920\nPlayResY: 1080\n",
+		"Style: Default,Arial,60,&H00FFFFFF,&H0000FFFF,&H00000000,&H7F000000,0,0,0,0,100,100,0,0,1,2,1,2,20,20,40,1\n",
+		"Dialogue: 0,0:00:01.00,0:00:02.50,Default,,0,0,0,,hello ｛world｝\n",
+		"Dialogue: 0,1:02:03.00,1:02:04.00,Default,,0,0,0,,测试字幕\n",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("ToASS() 缺少 %q\n%s", want, got)
+		}
+	}
+
+	style := DefaultASSStyle()
+	style.Name = "Karaoke"
+	style.FontName = "Noto Sans CJK SC"
+	style.PrimaryColor = color.NRGBA{R: 0x12, G: 0x34, B: 0x56, A: 0xff}
+	style.Bold = true
+	got = result.ToASSWithOptions(ASSOptions{
+		Title:    "demo",
+		PlayResX: 1280,
+		PlayResY: 720,
+		Style:    &style,
+		Karaoke:  true,
+	})
+	for _, want := range []string{
+		"Title: demo\n",
+		"PlayResX: 1280\nPlayResY: 720\n",
+		"Style: Karaoke,Noto Sans CJK SC,60,&H00563412,",
+		",-1,0,0,0,",
+		"Dialogue: 0,0:00:01.00,0:00:02.50,Karaoke,,0,0,0,,{\\k50}hello {\\k20}{\\k80}world\n",
+		"Dialogue: 0,1:02:03.00,1:02:04.00,Karaoke,,0,0,0,,测试字幕\n",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("ToASSWithOptions() 缺少 %q\n%s", want, got)
+		}
+	}
+}
@@ -0,0 +1,230 @@
+package types
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+)
+
+// FormatOptions 各输出格式的选项
+type FormatOptions struct {
+	VTT  VTTOptions  // WebVTT 选项
+	ASS  ASSOptions  // ASS 选项
+	LRC  LRCOptions  // LRC 选项
+	TTML TTMLOptions // TTML 和 EBU-TT-D 选项
+}
+
+// Formatter 输出格式，通过 RegisterFormatter 注册后可用于 ConvertToSubtitle 和命令行 -f 参数
+type Formatter interface {
+	// Name 格式名，如 "srt"
+	Name() string
+	// Extension 文件扩展名（不含点），如 "srt"
+	Extension() string
+	// Write 将识别结果写入 w
+	Write(w io.Writer, result *ASRResult, opts FormatOptions) error
+}
+
+// registeredFormatter 已注册的输出格式，名称和扩展名统一为小写
+type registeredFormatter struct {
+	name string
+	ext  string
+	f    Formatter
+}
+
+var (
+	formattersMu  sync.RWMutex
+	formatters    []registeredFormatter
+	outputFormats []string
+)
+
+// RegisterFormatter 注册输出格式，格式名和扩展名不区分大小写，同名格式会被替换
+//
+// f 为 nil 或格式名为空时 panic。
+func RegisterFormatter(f Formatter) {
+	if f == nil {
+		panic("types: RegisterFormatter formatter is nil")
+	}
+	if strings.TrimSpace(f.Name()) == "" {
+		panic("types: RegisterFormatter formatter name is empty")
+	}
+	entry := registeredFormatter{
+		name: strings.ToLower(f.Name()),
+		ext:  strings.ToLower(f.Extension()),
+		f:    f,
+	}
+
+	formattersMu.Lock()
+	defer formattersMu.Unlock()
+
+	for i, existing := range formatters {
+		if existing.name == entry.name {
+			formatters[i] = entry
+			return
+		}
+	}
+	formatters = append(formatters, entry)
+	outputFormats = append(outputFormats, entry.name)
+}
+
+// UnregisterFormatter 移除已注册的输出格式，不区分大小写，返回格式是否存在；主要用于测试中恢复注册表
+func UnregisterFormatter(name string) bool {
+	formattersMu.Lock()
+	defer formattersMu.Unlock()
+
+	name = strings.ToLower(name)
+	for i, entry := range formatters {
+		if entry.name == name {
+			formatters = append(formatters[:i:i], formatters[i+1:]...)
+			outputFormats = append(outputFormats[:i:i], outputFormats[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
+
+// LookupFormatter 按名称查找输出格式，不区分大小写
+func LookupFormatter(name string) (Formatter, bool) {
+	formattersMu.RLock()
+	defer formattersMu.RUnlock()
+
+	name = strings.ToLower(name)
+	for _, entry := range formatters {
+		if entry.name == name {
+			return entry.f, true
+		}
+	}
+	return nil, false
+}
+
+// Formatters 返回已注册的输出格式，按注册顺序排列
+func Formatters() []Formatter {
+	formattersMu.RLock()
+	defer formattersMu.RUnlock()
+	list := make([]Formatter, len(formatters))
+	for i, entry := range formatters {
+		list[i] = entry.f
+	}
+	return list
+}
+
+// OutputFormats 返回已注册的输出格式名
+func OutputFormats() []string {
+	formattersMu.RLock()
+	defer formattersMu.RUnlock()
+	return append([]string(nil), outputFormats...)
+}
+
+// FormatByExtension 按文件扩展名（不含点）查找输出格式，不区分大小写
+func FormatByExtension(ext string) (Formatter, bool) {
+	formattersMu.RLock()
+	defer formattersMu.RUnlock()
+
+	ext = strings.ToLower(strings.TrimPrefix(ext, "."))
+	for _, entry := range formatters {
+		if entry.ext == ext {
+			return entry.f, true
+		}
+	}
+	return nil, false
+}
+
+// FormatExtension 返回输出格式对应的文件扩展名（不含点），未注册的格式返回格式名
+func FormatExtension(format string) string {
+	if f, ok := LookupFormatter(format); ok {
+		return f.Extension()
+	}
+	return format
+}
+
+// FormatterFunc 以函数实现的输出格式
+type FormatterFunc struct {
+	FormatName string
+	Ext        string
+	WriteFunc  func(w io.Writer, result *ASRResult, opts FormatOptions) error
+	ParseFunc  func(r io.Reader) (*ASRResult, error) // 可选，为 nil 时不支持解析
+}
+
+// Name 格式名
+func (f FormatterFunc) Name() string { return f.FormatName }
+
+// Extension 文件扩展名，未设置时为格式名
+func (f FormatterFunc) Extension() string {
+	if f.Ext == "" {
+		return f.FormatName
+	}
+	return f.Ext
+}
+
+// Write 将识别结果写入 w
+func (f FormatterFunc) Write(w io.Writer, result *ASRResult, opts FormatOptions) error {
+	return f.WriteFunc(w, result, opts)
+}
+
+// Parse 将该格式的内容解析为识别结果
+func (f FormatterFunc) Parse(r io.Reader) (*ASRResult, error) {
+	if f.ParseFunc == nil {
+		return nil, fmt.Errorf("%w: %s", ErrParseUnsupported, f.FormatName)
+	}
+	return f.ParseFunc(r)
+}
+
+func init() {
+	RegisterFormatter(FormatterFunc{
+		FormatName: "srt",
+		WriteFunc: func(w io.Writer, r *ASRResult, _ FormatOptions) error {
+			return r.WriteSRT(w)
+		},
+		ParseFunc: ParseSRT,
+	})
+	RegisterFormatter(FormatterFunc{
+		FormatName: "json",
+		WriteFunc: func(w io.Writer, r *ASRResult, _ FormatOptions) error {
+			return r.WriteJSON(w)
+		},
+		ParseFunc: ParseJSON,
+	})
+	RegisterFormatter(FormatterFunc{
+		FormatName: "lrc",
+		WriteFunc: func(w io.Writer, r *ASRResult, opts FormatOptions) error {
+			return r.WriteLRC(w, opts.LRC)
+		},
+		ParseFunc: ParseLRC,
+	})
+	RegisterFormatter(FormatterFunc{
+		FormatName: "txt",
+		WriteFunc: func(w io.Writer, r *ASRResult, _ FormatOptions) error {
+			return r.WriteTXT(w)
+		},
+		ParseFunc: ParseTXT,
+	})
+	RegisterFormatter(FormatterFunc{
+		FormatName: "vtt",
+		WriteFunc: func(w io.Writer, r *ASRResult, opts FormatOptions) error {
+			return r.WriteVTT(w, opts.VTT)
+		},
+		ParseFunc: ParseVTT,
+	})
+	RegisterFormatter(FormatterFunc{
+		FormatName: "ass",
+		WriteFunc: func(w io.Writer, r *ASRResult, opts FormatOptions) error {
+			return r.WriteASS(w, opts.ASS)
+		},
+		ParseFunc: ParseASS,
+	})
+	RegisterFormatter(FormatterFunc{
+		FormatName: "ttml",
+		WriteFunc: func(w io.Writer, r *ASRResult, opts FormatOptions) error {
+			return r.WriteTTML(w, opts.TTML)
+		},
+		ParseFunc: ParseTTML,
+	})
+	RegisterFormatter(FormatterFunc{
+		FormatName: "ebuttd",
+		Ext:        "xml",
+		WriteFunc: func(w io.Writer, r *ASRResult, opts FormatOptions) error {
+			return r.WriteEBUTTD(w, opts.TTML)
+		},
+		ParseFunc: ParseTTML,
+	})
+}
@@ -0,0 +1,182 @@
+package types
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestBuiltinFormatters(t *testing.T) {
+	result := &ASRResult{
+		Utterances: []Utterance{
+			{StartTime: 1000, EndTime: 2500, Transcript: "测试字幕"},
+		},
+	}
+
+	if got := OutputFormats(); strings.Join(got, ",") != strings.Join(SupportedOutputFormats, ",") {
+		t.Errorf("OutputFormats() = %v, want built-in formats %v", got, SupportedOutputFormats)
+	}
+	for _, name := range SupportedOutputFormats {
+		f, ok := LookupFormatter(name)
+		if !ok {
+			t.Errorf("内置格式 %s 未注册", name)
+			continue
+		}
+		var buf bytes.Buffer
+		if err := f.Write(&buf, result, FormatOptions{}); err != nil {
+			t.Errorf("%s Write() error = %v", name, err)
+		}
+		if !strings.Contains(buf.String(), "测试字幕") {
+			t.Errorf("%s 输出缺少字幕文本: %q", name, buf.String())
+		}
+	}
+
+	if got := FormatExtension("ebuttd"); got != "xml" {
+		t.Errorf("FormatExtension(ebuttd) = %q, want xml", got)
+	}
+	if got := FormatExtension("srt"); got != "srt" {
+		t.Errorf("FormatExtension(srt) = %q, want srt", got)
+	}
+}
+
+func TestRegisterFormatter(t *testing.T) {
+	upper := func(w io.Writer, r *ASRResult, _ FormatOptions) error {
+		for _, u := range r.Utterances {
+			if _, err := io.WriteString(w, strings.ToUpper(u.Transcript)+"\n"); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	RegisterFormatter(FormatterFunc{FormatName: "test-upper", Ext: "up", WriteFunc: upper})
+	t.Cleanup(func() { UnregisterFormatter("test-upper") })
+
+	f, ok := LookupFormatter("TEST-UPPER")
+	if !ok {
+		t.Fatal("LookupFormatter() 未找到注册的格式")
+	}
+	var buf bytes.Buffer
+	if err := f.Write(&buf, &ASRResult{Utterances: []Utterance{{Transcript: "hello"}}}, FormatOptions{}); err != nil {
+		t.Fatalf("Write() error = %v", err)
+	}
+	if buf.String() != "HELLO\n" {
+		t.Errorf("Write() = %q, want %q", buf.String(), "HELLO\n")
+	}
+	if got := FormatExtension("test-upper"); got != "up" {
+		t.Errorf("FormatExtension() = %q, want up", got)
+	}
+
+	count := func() int {
+		n := 0
+		for _, name := range OutputFormats() {
+			if name == "test-upper" {
+				n++
+			}
+		}
+		return n
+	}
+	if count() != 1 {
+		t.Fatalf("OutputFormats() = %v, 应包含一次 test-upper", OutputFormats())
+	}
+
+	// 同名格式替换原有实现
+	RegisterFormatter(FormatterFunc{FormatName: "test-upper", Ext: "up2", WriteFunc: upper})
+	if count() != 1 {
+		t.Errorf("重复注册后 OutputFormats() = %v", OutputFormats())
+	}
+	if got := FormatExtension("test-upper"); got != "up2" {
+		t.Errorf("替换后 FormatExtension() = %q, want up2", got)
+	}
+}
+
+func TestUnregisterFormatter(t *testing.T) {
+	before := OutputFormats()
+	RegisterFormatter(FormatterFunc{FormatName: "test-remove", WriteFunc: func(io.Writer, *ASRResult, FormatOptions) error {
+		return nil
+	}})
+	if !UnregisterFormatter("TEST-REMOVE") {
+		t.Fatal("UnregisterFormatter() = false, want true")
+	}
+	if _, ok := LookupFormatter("test-remove"); ok {
+		t.Error("移除后仍能找到格式")
+	}
+	if got := OutputFormats(); !reflect.DeepEqual(got, before) {
+		t.Errorf("OutputFormats() = %v, want %v", got, before)
+	}
+	if UnregisterFormatter("test-remove") {
+		t.Error("重复移除应返回 false")
+	}
+}
+
+func TestRegisterFormatter_UpperCase(t *testing.T) {
+	RegisterFormatter(FormatterFunc{FormatName: "Test-CSV", Ext: "CSV", WriteFunc: func(io.Writer, *ASRResult, FormatOptions) error {
+		return nil
+	}})
+	t.Cleanup(func() { UnregisterFormatter("test-csv") })
+
+	for _, name := range []string{"Test-CSV", "test-csv", "TEST-CSV"} {
+		if _, ok := LookupFormatter(name); !ok {
+			t.Errorf("LookupFormatter(%q) 未找到注册的格式", name)
+		}
+	}
+	if f, ok := FormatByExtension("csv"); !ok || f.Name() != "Test-CSV" {
+		t.Errorf("FormatByExtension(csv) = %v, %v", f, ok)
+	}
+	found := false
+	for _, name := range OutputFormats() {
+		if name == "test-csv" {
+			found = true
+		}
+	}
+	if !found {
+		t.Errorf("OutputFormats() = %v, 应包含小写的 test-csv", OutputFormats())
+	}
+}
+
+func TestRegisterFormatter_Invalid(t *testing.T) {
+	tests := []struct {
+		name string
+		f    Formatter
+	}{
+		{"nil", nil},
+		{"empty name", FormatterFunc{FormatName: " "}},
+	}
+	for _, tt := range tests {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("%s: RegisterFormatter() 应 panic", tt.name)
+				}
+			}()
+			RegisterFormatter(tt.f)
+		}()
+	}
+}
+
+func TestOutputFormats_Concurrent(t *testing.T) {
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(2)
+		name := fmt.Sprintf("test-race-%d", i)
+		t.Cleanup(func() { UnregisterFormatter(name) })
+		go func() {
+			defer wg.Done()
+			RegisterFormatter(FormatterFunc{FormatName: name, WriteFunc: func(io.Writer, *ASRResult, FormatOptions) error {
+				return nil
+			}})
+		}()
+		go func() {
+			defer wg.Done()
+			formats := OutputFormats()
+			formats[0] = "modified"
+		}()
+	}
+	wg.Wait()
+	if OutputFormats()[0] != "srt" {
+		t.Errorf("修改 OutputFormats() 的返回值不应影响注册表: %v", OutputFormats())
+	}
+}
@@ -0,0 +1,218 @@
+package types
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// LRCOptions LRC 输出选项，标签为空或为 0 时不输出
+type LRCOptions struct {
+	WordTimestamps bool   // 输出增强型（A2）LRC，每个词前插入 <mm:ss.xx> 时间标签
+	Title          string // [ti:] 歌曲名
+	Artist         string // [ar:] 歌手
+	Album          string // [al:] 专辑
+	By             string // [by:] 歌词作者
+	Length         int64  // [length:] 时长（毫秒）
+	Offset         int64  // [offset:] 整体时间偏移（毫秒），正数表示歌词提前
+}
+
+// ToLRC 将识别结果转换为LRC格式
+func (r *ASRResult) ToLRC() string {
+	return r.ToLRCWithOptions(LRCOptions{})
+}
+
+// ToLRCWithOptions 按选项将识别结果转换为LRC格式
+func (r *ASRResult) ToLRCWithOptions(opts LRCOptions) string {
+	var b strings.Builder
+	r.WriteLRC(&b, opts)
+	return b.String()
+}
+
+// WriteLRC 将LRC格式的识别结果写入 w
+func (r *ASRResult) WriteLRC(w io.Writer, opts LRCOptions) error {
+	b := bufio.NewWriter(w)
+	writeLRCTag(b, "ti", opts.Title)
+	writeLRCTag(b, "ar", opts.Artist)
+	writeLRCTag(b, "al", opts.Album)
+	writeLRCTag(b, "by", opts.By)
+	if opts.Length > 0 {
+		writeLRCTag(b, "length", formatLRCLength(opts.Length))
+	}
+	if opts.Offset != 0 {
+		writeLRCTag(b, "offset", fmt.Sprintf("%d", opts.Offset))
+	}
+
+	for _, u := range r.Utterances {
+		fmt.Fprintf(b, "[%s]%s\n",
+			formatLRCTimestamp(u.StartTime),
+			lrcLineText(u, opts))
+	}
+	return b.Flush()
+}
+
+// lrcLineText 生成歌词文本，增强模式下以最后一个词的结束时间收尾
+func lrcLineText(u Utterance, opts LRCOptions) string {
+	if !opts.WordTimestamps || len(u.Words) == 0 {
+		return u.Transcript
+	}
+
+	var (
+		b    strings.Builder
+		prev string
+	)
+	for _, w := range u.Words {
+		if needSpace(prev, w.Label) {
+			b.WriteByte(' ')
+		}
+		fmt.Fprintf(&b, "<%s>%s", formatLRCTimestamp(w.StartTime), w.Label)
+		prev = w.Label
+	}
+	fmt.Fprintf(&b, "<%s>", formatLRCTimestamp(u.Words[len(u.Words)-1].EndTime))
+	return b.String()
+}
+
+func writeLRCTag(w io.Writer, tag, value string) {
+	if value != "" {
+		fmt.Fprintf(w, "[%s:%s]\n", tag, value)
+	}
+}
+
+// formatLRCTimestamp 格式化为 mm:ss.xx，超过 99 分钟时分钟数会溢出为三位，改用 h:mm:ss.xx
+//
+// 播放器无法解析三位的分钟数，h:mm:ss.xx 是其中较常见的扩展写法，ParseLRC 同样支持。
+func formatLRCTimestamp(ts int64) string {
+	if ts >= 100*60000 {
+		return fmt.Sprintf("%d:%02d:%02d.%02d",
+			ts/3600000, (ts/60000)%60, (ts/1000)%60, (ts%1000)/10)
+	}
+	return fmt.Sprintf("%02d:%02d.%02d",
+		ts/60000, (ts/1000)%60, (ts%1000)/10)
+}
+
+// formatLRCLength 格式化 [length:] 标签为 mm:ss，超过 99 分钟时与 formatLRCTimestamp 一样改用 h:mm:ss
+func formatLRCLength(ms int64) string {
+	sec := ms / 1000
+	if sec >= 100*60 {
+		return fmt.Sprintf("%d:%02d:%02d", sec/3600, (sec/60)%60, sec%60)
+	}
+	return fmt.Sprintf("%02d:%02d", sec/60, sec%60)
+}
+
+// ParseLRC 解析LRC歌词，支持一行多个时间标签和增强型（A2）的 <mm:ss.xx> 逐词时间
+//
+// LRC 只记录每句的开始时间，结束时间取下一句的开始时间；增强型歌词以最后一个时间标签为结束时间。
+// [offset:] 标签会应用到所有时间上。
+func ParseLRC(r io.Reader) (*ASRResult, error) {
+	lines, err := readLines(r)
+	if err != nil {
+		return nil, err
+	}
+
+	type lrcLine struct {
+		time int64
+		text string
+	}
+	var (
+		entries        []lrcLine
+		offset, length int64
+	)
+	for _, line := range lines {
+		line = strings.TrimSpace(line)
+		var times []int64
+		for strings.HasPrefix(line, "[") {
+			j := strings.IndexByte(line, ']')
+			if j < 0 {
+				break
+			}
+			tag := line[1:j]
+			if ts, err := parseClock(tag); err == nil {
+				times = append(times, ts)
+			} else if key, value, ok := strings.Cut(tag, ":"); ok {
+				switch strings.ToLower(strings.TrimSpace(key)) {
+				case "offset":
+					offset, _ = strconv.ParseInt(strings.TrimSpace(value), 10, 64)
+				case "length":
+					length, _ = parseClock(strings.TrimSpace(value))
+				}
+			}
+			line = line[j+1:]
+		}
+		for _, ts := range times {
+			entries = append(entries, lrcLine{time: ts, text: line})
+		}
+	}
+	sort.SliceStable(entries, func(a, b int) bool {
+		return entries[a].time < entries[b].time
+	})
+
+	result := &ASRResult{}
+	for i, e := range entries {
+		// 没有文本的时间标签只表示上一句结束
+		if strings.TrimSpace(e.text) == "" {
+			continue
+		}
+		end := e.time
+		if i+1 < len(entries) {
+			end = entries[i+1].time
+		} else if length > e.time {
+			end = length
+		}
+
+		u := Utterance{StartTime: e.time, EndTime: end}
+		u.Transcript, u.Words = parseLRCText(e.text, e.time, end)
+		if n := len(u.Words); n > 0 && u.Words[n-1].EndTime > u.StartTime {
+			u.EndTime = u.Words[n-1].EndTime
+		}
+		result.Utterances = append(result.Utterances, u)
+	}
+
+	// 正的偏移量表示歌词提前显示
+	if offset != 0 {
+		for i := range result.Utterances {
+			u := &result.Utterances[i]
+			u.StartTime -= offset
+			u.EndTime -= offset
+			for k := range u.Words {
+				u.Words[k].StartTime -= offset
+				u.Words[k].EndTime -= offset
+			}
+		}
+	}
+	return result, nil
+}
+
+// parseLRCText 解析增强型歌词的逐词时间标签，结尾的时间标签为最后一个词的结束时间
+func parseLRCText(text string, start, end int64) (string, []Words) {
+	segments := []timedSegment{{start: start, end: -1}}
+	var transcript strings.Builder
+	for text != "" {
+		i := strings.IndexByte(text, '<')
+		j := strings.IndexByte(text, '>')
+		if i < 0 || j < i {
+			segments[len(segments)-1].text += text
+			transcript.WriteString(text)
+			break
+		}
+		ts, err := parseClock(text[i+1 : j])
+		if err != nil {
+			// 不是时间标签，作为普通文本
+			segments[len(segments)-1].text += text[:j+1]
+			transcript.WriteString(text[:j+1])
+			text = text[j+1:]
+			continue
+		}
+		segments[len(segments)-1].text += text[:i]
+		transcript.WriteString(text[:i])
+		segments = append(segments, timedSegment{start: ts, end: -1})
+		text = text[j+1:]
+	}
+
+	if len(segments) == 1 {
+		return transcript.String(), nil
+	}
+	return strings.TrimSpace(transcript.String()), segmentsToWords(segments, end)
+}
@@ -0,0 +1,84 @@
+package types
+
+import (
+	"testing"
+)
+
+func TestASRResult_ToLRCWithOptions(t *testing.T) {
+	result := &ASRResult{
+		Utterances: []Utterance{
+			{
+				StartTime:  1000,
+				EndTime:    2500,
+				Transcript: "hello world",
+				Words: []Words{
+					{Label: "hello", StartTime: 1000, EndTime: 1500},
+					{Label: "world", StartTime: 1700, EndTime: 2500},
+				},
+			},
+			{
+				StartTime:  6001230,
+				EndTime:    6003000,
+				Transcript: "测试字幕",
+				Words: []Words{
+					{Label: "测试", StartTime: 6001230, EndTime: 6002000},
+					{Label: "字幕", StartTime: 6002000, EndTime: 6003000},
+				},
+			},
+		},
+	}
+
+	tests := []struct {
+		name string
+		opts LRCOptions
+		want string
+	}{
+		{
+			name: "默认",
+			want: "[00:01.00]hello world\n" +
+				"[1:40:01.23]测试字幕\n",
+		},
+		{
+			name: "增强模式和标签",
+			opts: LRCOptions{
+				WordTimestamps: true,
+				Title:          "标题",
+				Artist:         "歌手",
+				Album:          "专辑",
+				By:             "bcut-asr",
+				Length:         6003000,
+				Offset:         -500,
+			},
+			want: "[ti:标题]\n[ar:歌手]\n[al:专辑]\n[by:bcut-asr]\n[length:1:40:03]\n[offset:-500]\n" +
+				"[00:01.00]<00:01.00>hello <00:01.70>world<00:02.50>\n" +
+				"[1:40:01.23]<1:40:01.23>测试<1:40:02.00>字幕<1:40:03.00>\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := result.ToLRCWithOptions(tt.opts); got != tt.want {
+				t.Errorf("ToLRCWithOptions() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFormatLRCTimestamp(t *testing.T) {
+	tests := []struct {
+		ts   int64
+		want string
+	}{
+		{0, "00:00.00"},
+		{61230, "01:01.23"},
+		{99*60000 + 59990, "99:59.99"},
+		{75*60000 + 3200, "75:03.20"},
+		{100 * 60000, "1:40:00.00"},
+		{3*3600000 + 5*60000 + 7000, "3:05:07.00"},
+	}
+	for _, tt := range tests {
+		if got := formatLRCTimestamp(tt.ts); got != tt.want {
+			t.Errorf("formatLRCTimestamp(%d) = %q, want %q", tt.ts, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,41 @@
+package types
+
+import "time"
+
+// MediaInfo 媒体文件信息
+type MediaInfo struct {
+	FormatName   string        // 容器格式，如 "mov,mp4,m4a,3gp,3g2,mj2"
+	Duration     time.Duration // 时长，未知时为 0
+	Size         int64         // 文件大小（字节），未知时为 0
+	BitRate      int64         // 总码率（bit/s），未知时为 0
+	AudioStreams []AudioStream // 音频流，没有音频时为空
+}
+
+// AudioStream 音频流信息
+type AudioStream struct {
+	Index         int           // 流在文件中的序号
+	Codec         string        // 编码，如 "aac"、"mp3"
+	Profile       string        // 编码配置，如 "LC"
+	SampleRate    int           // 采样率（Hz）
+	Channels      int           // 声道数
+	ChannelLayout string        // 声道布局，如 "stereo"
+	BitRate       int64         // 码率（bit/s），未知时为 0
+	Duration      time.Duration // 时长，未知时为 0
+}
+
+// HasAudio 是否包含音频流
+func (m *MediaInfo) HasAudio() bool {
+	return len(m.AudioStreams) > 0
+}
+
+// Silence 静音区间
+type Silence struct {
+	Start time.Duration
+	End   time.Duration
+}
+
+// TimeRange 时间区间 [Start, End)
+type TimeRange struct {
+	Start time.Duration
+	End   time.Duration
+}
@@ -0,0 +1,153 @@
+package types
+
+import (
+	"math"
+	"sort"
+	"strings"
+)
+
+// ResultPart 分段识别的结果，Result 中的时间相对于分段开始
+type ResultPart struct {
+	Result *ASRResult
+	Start  int64 // 分段在完整音频中的开始时间（毫秒）
+	End    int64 // 分段在完整音频中的结束时间（毫秒），与下一段重叠时用于去重，0 表示未知
+}
+
+// MergeResults 将分段识别的结果合并到同一时间轴
+//
+// 每段的句子和词按 Start 平移。相邻两段重叠时以重叠区间的中点为界，句子按中间时刻归属到前一段或后一段；
+// 分界处与上一句在时间上重叠的重复句子、词和文本只保留一份。
+func MergeResults(parts []ResultPart) *ASRResult {
+	sorted := append([]ResultPart(nil), parts...)
+	sort.SliceStable(sorted, func(a, b int) bool {
+		return sorted[a].Start < sorted[b].Start
+	})
+
+	merged := &ASRResult{}
+	for i, part := range sorted {
+		if part.Result == nil {
+			continue
+		}
+		if merged.Version == "" {
+			merged.Version = part.Result.Version
+		}
+
+		lo, hi := int64(math.MinInt64), int64(math.MaxInt64)
+		if i > 0 {
+			lo = partBoundary(sorted[i-1], part)
+		}
+		if i+1 < len(sorted) {
+			hi = partBoundary(part, sorted[i+1])
+		}
+
+		// 只检查分界处的句子，直到保留了本段的第一句
+		atBoundary := len(merged.Utterances) > 0
+		for _, u := range part.Result.Utterances {
+			u = shiftUtterance(u, part.Start)
+			if mid := u.StartTime + (u.EndTime-u.StartTime)/2; mid < lo || mid >= hi {
+				continue
+			}
+			if atBoundary {
+				var keep bool
+				if u, keep = trimOverlap(merged.Utterances[len(merged.Utterances)-1], u); !keep {
+					continue
+				}
+				atBoundary = false
+			}
+			merged.Utterances = append(merged.Utterances, u)
+		}
+	}
+	return merged
+}
+
+// partBoundary 相邻两段的分界：重叠时为重叠区间的中点，否则为后一段的开始
+func partBoundary(a, b ResultPart) int64 {
+	if a.End > b.Start {
+		return b.Start + (a.End-b.Start)/2
+	}
+	return b.Start
+}
+
+// shiftUtterance 返回时间平移 offset 后的句子，不修改原来的词
+func shiftUtterance(u Utterance, offset int64) Utterance {
+	u.StartTime += offset
+	u.EndTime += offset
+	if u.Words != nil {
+		words := make([]Words, len(u.Words))
+		for i, w := range u.Words {
+			w.StartTime += offset
+			w.EndTime += offset
+			words[i] = w
+		}
+		u.Words = words
+	}
+	return u
+}
+
+// minTextOverlap 没有词级时间戳时，按文本去重要求的最少重复字符数
+const minTextOverlap = 2
+
+// trimOverlap 去掉 u 开头与上一句 prev 结尾重复的内容，u 完全重复时返回 false
+//
+// 只有与 prev 在时间上重叠的句子才可能是重复识别的内容。
+func trimOverlap(prev, u Utterance) (Utterance, bool) {
+	if u.StartTime >= prev.EndTime {
+		return u, true
+	}
+	if strings.TrimSpace(u.Transcript) == strings.TrimSpace(prev.Transcript) {
+		return u, false
+	}
+
+	if len(u.Words) > 0 {
+		// 上一句结束前的词已由上一段识别
+		k := 0
+		for k < len(u.Words) && u.Words[k].StartTime+(u.Words[k].EndTime-u.Words[k].StartTime)/2 < prev.EndTime {
+			k++
+		}
+		if k == len(u.Words) {
+			return u, false
+		}
+		if k > 0 {
+			u.Words = u.Words[k:]
+			u.StartTime = u.Words[0].StartTime
+			u.Transcript = wordsText(u.Words)
+		}
+		return u, true
+	}
+
+	// 没有词级时间戳，去掉开头与上一句结尾相同的文本
+	prevText, text := []rune(prev.Transcript), []rune(u.Transcript)
+	k := overlapRunes(prevText, text)
+	if k < minTextOverlap {
+		return u, true
+	}
+	rest := strings.TrimSpace(string(text[k:]))
+	if rest == "" {
+		return u, false
+	}
+	u.Transcript = rest
+	return u, true
+}
+
+// overlapRunes 返回 a 的后缀与 b 的前缀相同的最多字符数
+func overlapRunes(a, b []rune) int {
+	n := len(a)
+	if len(b) < n {
+		n = len(b)
+	}
+	for k := n; k > 0; k-- {
+		if string(a[len(a)-k:]) == string(b[:k]) {
+			return k
+		}
+	}
+	return 0
+}
+
+// wordsText 拼接词得到句子文本
+func wordsText(words []Words) string {
+	var text string
+	for _, w := range words {
+		text = joinText(text, w.Label)
+	}
+	return text
+}
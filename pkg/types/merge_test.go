@@ -0,0 +1,141 @@
+package types
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestMergeResults(t *testing.T) {
+	first := &ASRResult{
+		Version: "v1",
+		Utterances: []Utterance{
+			{StartTime: 1000, EndTime: 2000, Transcript: "第一段", Words: []Words{{Label: "第一段", StartTime: 1000, EndTime: 2000}}},
+		},
+	}
+	second := &ASRResult{
+		Utterances: []Utterance{
+			{StartTime: 500, EndTime: 1500, Transcript: "第二段", Words: []Words{{Label: "第二段", StartTime: 500, EndTime: 1500}}},
+		},
+	}
+
+	// 分段顺序不影响结果
+	got := MergeResults([]ResultPart{
+		{Result: second, Start: 60000, End: 120000},
+		{Result: first, Start: 0, End: 60000},
+	})
+	want := &ASRResult{
+		Version: "v1",
+		Utterances: []Utterance{
+			{StartTime: 1000, EndTime: 2000, Transcript: "第一段", Words: []Words{{Label: "第一段", StartTime: 1000, EndTime: 2000}}},
+			{StartTime: 60500, EndTime: 61500, Transcript: "第二段", Words: []Words{{Label: "第二段", StartTime: 60500, EndTime: 61500}}},
+		},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("MergeResults() = %+v, want %+v", got, want)
+	}
+	if second.Utterances[0].Words[0].StartTime != 500 {
+		t.Error("MergeResults() 不应修改输入的结果")
+	}
+}
+
+func TestMergeResults_Overlap(t *testing.T) {
+	// 第一段 0-62 秒，第二段 58-120 秒，以 60 秒为界
+	first := &ASRResult{Utterances: []Utterance{
+		{StartTime: 10000, EndTime: 12000, Transcript: "开头"},
+		{StartTime: 57000, EndTime: 59200, Transcript: "hello world foo"},
+		{StartTime: 59300, EndTime: 60600, Transcript: "bar baz"},
+		// 中间时刻在分界之后，属于第二段
+		{StartTime: 61000, EndTime: 62000, Transcript: "重复的句子"},
+	}}
+	second := &ASRResult{Utterances: []Utterance{
+		// 中间时刻在分界之前，属于第一段
+		{StartTime: 0, EndTime: 1000, Transcript: "截断"},
+		// 断句与第一段不同，开头的词与上一句在时间上重叠
+		{StartTime: 700, EndTime: 3500, Transcript: "foo bar baz qux", Words: []Words{
+			{Label: "foo", StartTime: 700, EndTime: 1200},
+			{Label: "bar", StartTime: 1300, EndTime: 1800},
+			{Label: "baz", StartTime: 1900, EndTime: 2600},
+			{Label: "qux", StartTime: 2700, EndTime: 3500},
+		}},
+		{StartTime: 3000, EndTime: 4000, Transcript: "重复的句子"},
+		{StartTime: 10000, EndTime: 12000, Transcript: "结尾"},
+	}}
+
+	got := MergeResults([]ResultPart{
+		{Result: first, Start: 0, End: 62000},
+		{Result: second, Start: 58000, End: 120000},
+	})
+	want := []Utterance{
+		{StartTime: 10000, EndTime: 12000, Transcript: "开头"},
+		{StartTime: 57000, EndTime: 59200, Transcript: "hello world foo"},
+		{StartTime: 59300, EndTime: 60600, Transcript: "bar baz"},
+		{StartTime: 60700, EndTime: 61500, Transcript: "qux", Words: []Words{
+			{Label: "qux", StartTime: 60700, EndTime: 61500},
+		}},
+		{StartTime: 61000, EndTime: 62000, Transcript: "重复的句子"},
+		{StartTime: 68000, EndTime: 70000, Transcript: "结尾"},
+	}
+	if !reflect.DeepEqual(got.Utterances, want) {
+		t.Errorf("MergeResults() = %+v\nwant %+v", got.Utterances, want)
+	}
+}
+
+func TestTrimOverlap(t *testing.T) {
+	prev := Utterance{StartTime: 0, EndTime: 5000, Transcript: "今天天气很好"}
+	words := []Words{
+		{Label: "好", StartTime: 4200, EndTime: 4900},
+		{Label: "出去", StartTime: 5000, EndTime: 6000},
+	}
+	tests := []struct {
+		name string
+		u    Utterance
+		want Utterance
+		keep bool
+	}{
+		{
+			name: "no time overlap",
+			u:    Utterance{StartTime: 5000, EndTime: 6000, Transcript: "很好"},
+			want: Utterance{StartTime: 5000, EndTime: 6000, Transcript: "很好"},
+			keep: true,
+		},
+		{
+			name: "text overlap",
+			u:    Utterance{StartTime: 4000, EndTime: 8000, Transcript: "很好我们出去"},
+			want: Utterance{StartTime: 4000, EndTime: 8000, Transcript: "我们出去"},
+			keep: true,
+		},
+		{
+			name: "single rune",
+			u:    Utterance{StartTime: 4000, EndTime: 8000, Transcript: "好的"},
+			want: Utterance{StartTime: 4000, EndTime: 8000, Transcript: "好的"},
+			keep: true,
+		},
+		{
+			name: "words",
+			u:    Utterance{StartTime: 4200, EndTime: 6000, Transcript: "好出去", Words: words},
+			want: Utterance{StartTime: 5000, EndTime: 6000, Transcript: "出去", Words: words[1:]},
+			keep: true,
+		},
+		{
+			name: "all words overlap",
+			u:    Utterance{StartTime: 4200, EndTime: 4900, Transcript: "好", Words: words[:1]},
+			keep: false,
+		},
+		{
+			name: "contained",
+			u:    Utterance{StartTime: 4000, EndTime: 5000, Transcript: "天气很好"},
+			keep: false,
+		},
+		{
+			name: "same",
+			u:    Utterance{StartTime: 100, EndTime: 4900, Transcript: "今天天气很好"},
+			keep: false,
+		},
+	}
+	for _, tt := range tests {
+		got, keep := trimOverlap(prev, tt.u)
+		if keep != tt.keep || (keep && !reflect.DeepEqual(got, tt.want)) {
+			t.Errorf("%s: trimOverlap() = %+v, %v, want %+v, %v", tt.name, got, keep, tt.want, tt.keep)
+		}
+	}
+}
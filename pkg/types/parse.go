@@ -0,0 +1,189 @@
+package types
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// ErrParseUnsupported 输出格式不支持解析
+var ErrParseUnsupported = errors.New("不支持解析该格式")
+
+// Parser 可以将输出内容解析回识别结果的格式，内置格式均实现了该接口
+type Parser interface {
+	Parse(r io.Reader) (*ASRResult, error)
+}
+
+// ParseResult 按格式名解析字幕内容
+func ParseResult(r io.Reader, format string) (*ASRResult, error) {
+	f, ok := LookupFormatter(format)
+	if !ok {
+		return nil, fmt.Errorf("不支持的格式: %s", format)
+	}
+	p, ok := f.(Parser)
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrParseUnsupported, format)
+	}
+	return p.Parse(r)
+}
+
+// ParseSRT 解析SRT字幕
+func ParseSRT(r io.Reader) (*ASRResult, error) {
+	lines, err := readLines(r)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &ASRResult{}
+	for i := 0; i < len(lines); i++ {
+		// 序号和空行都不含时间轴，跳过
+		if !strings.Contains(lines[i], "-->") {
+			continue
+		}
+		start, end, err := parseTimeRange(lines[i])
+		if err != nil {
+			return nil, fmt.Errorf("第 %d 行: %w", i+1, err)
+		}
+		var text []string
+		for i+1 < len(lines) && strings.TrimSpace(lines[i+1]) != "" {
+			i++
+			text = append(text, lines[i])
+		}
+		result.Utterances = append(result.Utterances, Utterance{
+			StartTime:  start,
+			EndTime:    end,
+			Transcript: strings.Join(text, "\n"),
+		})
+	}
+	return result, nil
+}
+
+// ParseTXT 解析纯文本，每个非空行为一句，没有时间信息
+func ParseTXT(r io.Reader) (*ASRResult, error) {
+	lines, err := readLines(r)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &ASRResult{}
+	for _, line := range lines {
+		if strings.TrimSpace(line) != "" {
+			result.Utterances = append(result.Utterances, Utterance{Transcript: line})
+		}
+	}
+	return result, nil
+}
+
+// ParseJSON 解析JSON格式的识别结果
+func ParseJSON(r io.Reader) (*ASRResult, error) {
+	var result ASRResult
+	if err := json.NewDecoder(r).Decode(&result); err != nil {
+		return nil, fmt.Errorf("解析JSON失败: %w", err)
+	}
+	return &result, nil
+}
+
+// readLines 读取所有行，去掉开头的 BOM 和行尾的 \r
+func readLines(r io.Reader) ([]string, error) {
+	sc := bufio.NewScanner(r)
+	sc.Buffer(make([]byte, 64*1024), 16*1024*1024)
+
+	var lines []string
+	for sc.Scan() {
+		line := strings.TrimSuffix(sc.Text(), "\r")
+		if len(lines) == 0 {
+			line = strings.TrimPrefix(line, "\ufeff")
+		}
+		lines = append(lines, line)
+	}
+	return lines, sc.Err()
+}
+
+// parseTimeRange 解析 "开始 --> 结束" 时间轴，忽略结束时间后的 WebVTT 设置
+func parseTimeRange(line string) (int64, int64, error) {
+	parts := strings.SplitN(line, "-->", 2)
+	fields := strings.Fields(parts[1])
+	if len(fields) == 0 {
+		return 0, 0, fmt.Errorf("无效的时间轴: %q", line)
+	}
+	start, err := parseClock(parts[0])
+	if err != nil {
+		return 0, 0, err
+	}
+	end, err := parseClock(fields[0])
+	if err != nil {
+		return 0, 0, err
+	}
+	return start, end, nil
+}
+
+// parseClock 解析 [h:]mm:ss[.fff] 形式的时间为毫秒，小数部分可以用 , 或 . 分隔，位数不限
+func parseClock(s string) (int64, error) {
+	s = strings.TrimSpace(s)
+	clock := strings.Replace(s, ",", ".", 1)
+
+	var frac int64
+	if i := strings.LastIndexByte(clock, '.'); i >= 0 {
+		digits := clock[i+1:]
+		clock = clock[:i]
+		if digits == "" || !isDigits(digits) {
+			return 0, fmt.Errorf("无效的时间: %q", s)
+		}
+		digits = (digits + "00")[:3]
+		frac, _ = strconv.ParseInt(digits, 10, 64)
+	}
+
+	parts := strings.Split(clock, ":")
+	if len(parts) < 2 || len(parts) > 3 {
+		return 0, fmt.Errorf("无效的时间: %q", s)
+	}
+	var ms int64
+	for _, part := range parts {
+		if part == "" || !isDigits(part) {
+			return 0, fmt.Errorf("无效的时间: %q", s)
+		}
+		n, _ := strconv.ParseInt(part, 10, 64)
+		ms = ms*60 + n
+	}
+	return ms*1000 + frac, nil
+}
+
+func isDigits(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if s[i] < '0' || s[i] > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// timedSegment 带开始时间的文本片段，用于从内联时间标签还原词级时间戳
+type timedSegment struct {
+	start int64
+	end   int64 // 为 -1 时以下一片段的开始时间为结束时间
+	text  string
+}
+
+// segmentsToWords 将片段转换为词，空白片段只作为时间分隔
+func segmentsToWords(segments []timedSegment, end int64) []Words {
+	var words []Words
+	for i, seg := range segments {
+		label := strings.TrimSpace(seg.text)
+		if label == "" {
+			continue
+		}
+		wordEnd := seg.end
+		if wordEnd < 0 {
+			wordEnd = end
+			if i+1 < len(segments) {
+				wordEnd = segments[i+1].start
+			}
+		}
+		words = append(words, Words{Label: label, StartTime: seg.start, EndTime: wordEnd})
+	}
+	return words
+}
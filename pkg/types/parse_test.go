@@ -0,0 +1,223 @@
+package types
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func parseTestResult() *ASRResult {
+	return &ASRResult{
+		Utterances: []Utterance{
+			{
+				StartTime:  1000,
+				EndTime:    2500,
+				Transcript: "hello world",
+				Words: []Words{
+					{Label: "hello", StartTime: 1000, EndTime: 1700},
+					{Label: "world", StartTime: 1700, EndTime: 2500},
+				},
+			},
+			{
+				StartTime:  3720040,
+				EndTime:    3724000,
+				Transcript: "测试字幕",
+				Words: []Words{
+					{Label: "测试", StartTime: 3720040, EndTime: 3722000},
+					{Label: "字幕", StartTime: 3722000, EndTime: 3724000},
+				},
+			},
+		},
+	}
+}
+
+// withoutWords 只保留句子的时间和文本
+func withoutWords(r *ASRResult) *ASRResult {
+	out := &ASRResult{}
+	for _, u := range r.Utterances {
+		u.Words = nil
+		out.Utterances = append(out.Utterances, u)
+	}
+	return out
+}
+
+func TestParse_RoundTrip(t *testing.T) {
+	result := parseTestResult()
+
+	tests := []struct {
+		format string
+		opts   FormatOptions
+		want   *ASRResult
+	}{
+		{format: "json", want: result},
+		{format: "srt", want: withoutWords(result)},
+		{format: "vtt", want: withoutWords(result)},
+		{format: "vtt", opts: FormatOptions{VTT: VTTOptions{WordTimestamps: true}}, want: result},
+		{format: "ass", want: withoutWords(result)},
+		{format: "ass", opts: FormatOptions{ASS: ASSOptions{Karaoke: true}}, want: result},
+		{format: "lrc", opts: FormatOptions{LRC: LRCOptions{WordTimestamps: true, Offset: 200}}, want: result},
+		{format: "ttml", want: withoutWords(result)},
+		{format: "ttml", opts: FormatOptions{TTML: TTMLOptions{TickRate: 10000000}}, want: withoutWords(result)},
+		// 25 帧时 2.5 秒截断为第 12 帧
+		{format: "ttml", opts: FormatOptions{TTML: TTMLOptions{FrameRate: 25}}, want: func() *ASRResult {
+			r := withoutWords(result)
+			r.Utterances[0].EndTime = 2480
+			return r
+		}()},
+		{format: "ebuttd", want: withoutWords(result)},
+	}
+
+	for _, tt := range tests {
+		f, _ := LookupFormatter(tt.format)
+		var buf bytes.Buffer
+		if err := f.Write(&buf, result, tt.opts); err != nil {
+			t.Fatalf("%s Write() error = %v", tt.format, err)
+		}
+		output := buf.String()
+
+		got, err := ParseResult(&buf, tt.format)
+		if err != nil {
+			t.Fatalf("%s ParseResult() error = %v", tt.format, err)
+		}
+		if tt.format == "lrc" {
+			// 偏移量在解析时应用，写入时不改变时间
+			for i := range got.Utterances {
+				shift(&got.Utterances[i], 200)
+			}
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s %+v 解析结果 = %+v, want %+v\n%s", tt.format, tt.opts, got, tt.want, output)
+		}
+	}
+}
+
+func shift(u *Utterance, ms int64) {
+	u.StartTime += ms
+	u.EndTime += ms
+	for i := range u.Words {
+		u.Words[i].StartTime += ms
+		u.Words[i].EndTime += ms
+	}
+}
+
+func TestParseSRT(t *testing.T) {
+	input := "\ufeff1\r\n00:00:01,000 --> 00:00:02,500\r\n第一行\r\n第二行\r\n\r\n2\r\n00:01:00.5 --> 00:01:02,000\r\nnext\r\n"
+	got, err := ParseSRT(strings.NewReader(input))
+	if err != nil {
+		t.Fatalf("ParseSRT() error = %v", err)
+	}
+	want := []Utterance{
+		{StartTime: 1000, EndTime: 2500, Transcript: "第一行\n第二行"},
+		{StartTime: 60500, EndTime: 62000, Transcript: "next"},
+	}
+	if !reflect.DeepEqual(got.Utterances, want) {
+		t.Errorf("ParseSRT() = %+v, want %+v", got.Utterances, want)
+	}
+
+	if _, err := ParseSRT(strings.NewReader("1\n00:00:xx,000 --> 00:00:02,000\ntext\n")); err == nil {
+		t.Error("ParseSRT() 无效时间应返回错误")
+	}
+}
+
+func TestParseVTT(t *testing.T) {
+	input := "WEBVTT - title\n\nNOTE 注释\n不是字幕\n\nSTYLE\n::cue { color: red }\n\n" +
+		"intro\n00:01.000 --> 00:02.000 align:start line:0\n<v Speaker>Tom &amp; <b>Jerry</b></v>\n"
+	got, err := ParseVTT(strings.NewReader(input))
+	if err != nil {
+		t.Fatalf("ParseVTT() error = %v", err)
+	}
+	want := []Utterance{{StartTime: 1000, EndTime: 2000, Transcript: "Tom & Jerry"}}
+	if !reflect.DeepEqual(got.Utterances, want) {
+		t.Errorf("ParseVTT() = %+v, want %+v", got.Utterances, want)
+	}
+
+	if _, err := ParseVTT(strings.NewReader("1\n00:00:01,000 --> 00:00:02,000\ntext\n")); err == nil {
+		t.Error("ParseVTT() 缺少文件头应返回错误")
+	}
+}
+
+func TestParseLRC(t *testing.T) {
+	input := "[ti:标题]\n[length:00:20]\n[00:05.00][00:01.00]重复\n[00:03.00]第二句\n[00:04.00]\n"
+	got, err := ParseLRC(strings.NewReader(input))
+	if err != nil {
+		t.Fatalf("ParseLRC() error = %v", err)
+	}
+	want := []Utterance{
+		{StartTime: 1000, EndTime: 3000, Transcript: "重复"},
+		{StartTime: 3000, EndTime: 4000, Transcript: "第二句"},
+		{StartTime: 5000, EndTime: 20000, Transcript: "重复"},
+	}
+	if !reflect.DeepEqual(got.Utterances, want) {
+		t.Errorf("ParseLRC() = %+v, want %+v", got.Utterances, want)
+	}
+
+	// 超过 99 分钟的 h:mm:ss.xx 形式与分钟数超过 59 的 mm:ss.xx 形式都能解析
+	got, err = ParseLRC(strings.NewReader("[75:03.20]第一句\n[1:15:05.00]第二句\n[1:15:06.00]\n"))
+	if err != nil {
+		t.Fatalf("ParseLRC() error = %v", err)
+	}
+	want = []Utterance{
+		{StartTime: 4503200, EndTime: 4505000, Transcript: "第一句"},
+		{StartTime: 4505000, EndTime: 4506000, Transcript: "第二句"},
+	}
+	if !reflect.DeepEqual(got.Utterances, want) {
+		t.Errorf("ParseLRC() = %+v, want %+v", got.Utterances, want)
+	}
+}
+
+func TestParseASS(t *testing.T) {
+	input := "[Script Info]\nTitle: x\n\n[Events]\n" +
+		"Format: Layer, Start, End, Style, Text\n" +
+		"Comment: 0,0:00:00.00,0:00:01.00,Default,注释\n" +
+		"Dialogue: 0,0:00:01.50,0:00:03.00,Default,{\\an8\\b1}第一行\\N第二行, 逗号\n"
+	got, err := ParseASS(strings.NewReader(input))
+	if err != nil {
+		t.Fatalf("ParseASS() error = %v", err)
+	}
+	want := []Utterance{{StartTime: 1500, EndTime: 3000, Transcript: "第一行\n第二行, 逗号"}}
+	if !reflect.DeepEqual(got.Utterances, want) {
+		t.Errorf("ParseASS() = %+v, want %+v", got.Utterances, want)
+	}
+}
+
+func TestParseTTML(t *testing.T) {
+	input := `<?xml version="1.0" encoding="UTF-8"?>
+<tt xmlns="http://www.w3.org/ns/ttml" xmlns:ttp="http://www.w3.org/ns/ttml#parameter" ttp:frameRate="25">
+  <body>
+    <div>
+      <p begin="1.5s" dur="500ms">
+        第一行<br/>
+        <span>第二行</span>
+      </p>
+      <p begin="00:00:03:05" end="100f">帧</p>
+    </div>
+  </body>
+</tt>`
+	got, err := ParseTTML(strings.NewReader(input))
+	if err != nil {
+		t.Fatalf("ParseTTML() error = %v", err)
+	}
+	want := []Utterance{
+		{StartTime: 1500, EndTime: 2000, Transcript: "第一行\n第二行"},
+		{StartTime: 3200, EndTime: 4000, Transcript: "帧"},
+	}
+	if !reflect.DeepEqual(got.Utterances, want) {
+		t.Errorf("ParseTTML() = %+v, want %+v", got.Utterances, want)
+	}
+}
+
+func TestParseResult_Unsupported(t *testing.T) {
+	RegisterFormatter(FormatterFunc{
+		FormatName: "test-write-only",
+		WriteFunc:  func(io.Writer, *ASRResult, FormatOptions) error { return nil },
+	})
+	if _, err := ParseResult(strings.NewReader(""), "test-write-only"); !errors.Is(err, ErrParseUnsupported) {
+		t.Errorf("ParseResult() error = %v, want %v", err, ErrParseUnsupported)
+	}
+	if _, err := ParseResult(strings.NewReader(""), "unknown"); err == nil {
+		t.Error("ParseResult() 未注册的格式应返回错误")
+	}
+}
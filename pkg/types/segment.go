@@ -0,0 +1,153 @@
+package types
+
+import (
+	"strings"
+	"unicode/utf8"
+)
+
+// SegmentOptions 字幕断句选项
+type SegmentOptions struct {
+	MaxDuration int64 // 单条字幕最大时长（毫秒），0 表示不限制
+	MaxGap      int64 // 词间停顿超过该值（毫秒）时断句，0 表示不合并中间有停顿的句子
+	MaxChars    int   // 单条字幕最大字符数，0 表示不限制
+}
+
+// IsZero 是否未设置任何断句条件
+func (o SegmentOptions) IsZero() bool {
+	return o.MaxDuration <= 0 && o.MaxGap <= 0 && o.MaxChars <= 0
+}
+
+// segWord 断句时使用的词，记录所属句子
+type segWord struct {
+	word      Words
+	utterance int
+	pseudo    bool // 句子没有词级时间戳时，整句视为一个词
+}
+
+// Resegment 根据词级时间戳重新断句
+//
+// 所有句子的词按时间顺序展开后重新组合：停顿超过 MaxGap、时长超过 MaxDuration
+// 或字符数超过 MaxChars 时开始新的一条字幕，停顿较短的相邻句子会被合并。
+// MaxGap 为 0 时只合并首尾相接的句子，避免一条字幕跨过中间的静音。
+// 没有词级时间戳的句子作为一个整体参与断句。未设置任何条件时返回原结果的副本。
+func (r *ASRResult) Resegment(opts SegmentOptions) *ASRResult {
+	out := &ASRResult{Version: r.Version}
+	if opts.IsZero() {
+		out.Utterances = append([]Utterance(nil), r.Utterances...)
+		return out
+	}
+
+	var words []segWord
+	for i, u := range r.Utterances {
+		if len(u.Words) == 0 {
+			words = append(words, segWord{
+				word:      Words{Label: u.Transcript, StartTime: u.StartTime, EndTime: u.EndTime},
+				utterance: i,
+				pseudo:    true,
+			})
+			continue
+		}
+		for _, w := range u.Words {
+			words = append(words, segWord{word: w, utterance: i})
+		}
+	}
+
+	var (
+		cue   []segWord
+		chars int
+	)
+	flush := func() {
+		if len(cue) > 0 {
+			out.Utterances = append(out.Utterances, r.buildCue(cue))
+		}
+		cue = nil
+		chars = 0
+	}
+
+	for _, w := range words {
+		if len(cue) > 0 {
+			last := cue[len(cue)-1].word
+			n := utf8.RuneCountInString(w.word.Label)
+			if needSpace(last.Label, w.word.Label) {
+				n++
+			}
+			switch {
+			case opts.MaxGap > 0 && w.word.StartTime-last.EndTime > opts.MaxGap:
+				flush()
+			case opts.MaxGap <= 0 && w.utterance != cue[len(cue)-1].utterance && w.word.StartTime > last.EndTime:
+				flush()
+			case opts.MaxDuration > 0 && w.word.EndTime-cue[0].word.StartTime > opts.MaxDuration:
+				flush()
+			case opts.MaxChars > 0 && chars+n > opts.MaxChars:
+				flush()
+			default:
+				chars += n
+				cue = append(cue, w)
+				continue
+			}
+		}
+		chars = utf8.RuneCountInString(w.word.Label)
+		cue = append(cue, w)
+	}
+	flush()
+
+	return out
+}
+
+// buildCue 由一组词生成一条字幕，完整包含的句子沿用原文本
+func (r *ASRResult) buildCue(cue []segWord) Utterance {
+	u := Utterance{
+		StartTime: cue[0].word.StartTime,
+		EndTime:   cue[len(cue)-1].word.EndTime,
+	}
+
+	var text string
+	for start := 0; start < len(cue); {
+		end := start
+		for end < len(cue) && cue[end].utterance == cue[start].utterance {
+			end++
+		}
+
+		src := r.Utterances[cue[start].utterance]
+		var part string
+		if cue[start].pseudo || end-start == len(src.Words) {
+			part = src.Transcript
+		} else {
+			for _, w := range cue[start:end] {
+				part = joinText(part, w.word.Label)
+			}
+		}
+		text = joinText(text, part)
+
+		for _, w := range cue[start:end] {
+			if !w.pseudo {
+				u.Words = append(u.Words, w.word)
+			}
+		}
+		start = end
+	}
+	u.Transcript = text
+
+	return u
+}
+
+// joinText 拼接两段文本，英文单词之间补充空格
+func joinText(a, b string) string {
+	if needSpace(a, b) {
+		return a + " " + b
+	}
+	return a + b
+}
+
+func needSpace(a, b string) bool {
+	if a == "" || b == "" {
+		return false
+	}
+	last, _ := utf8.DecodeLastRuneInString(a)
+	first, _ := utf8.DecodeRuneInString(b)
+	return isWordRune(last) && isWordRune(first) && !strings.HasSuffix(a, " ")
+}
+
+func isWordRune(r rune) bool {
+	return r < utf8.RuneSelf && (r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || r == '\'')
+}
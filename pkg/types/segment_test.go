@@ -0,0 +1,125 @@
+package types
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestASRResult_Resegment(t *testing.T) {
+	result := &ASRResult{
+		Utterances: []Utterance{
+			{
+				StartTime:  0,
+				EndTime:    3000,
+				Transcript: "今天天气很好",
+				Words: []Words{
+					{Label: "今天", StartTime: 0, EndTime: 500},
+					{Label: "天气", StartTime: 600, EndTime: 1200},
+					{Label: "很", StartTime: 2500, EndTime: 2700},
+					{Label: "好", StartTime: 2700, EndTime: 3000},
+				},
+			},
+			{
+				StartTime:  3100,
+				EndTime:    4000,
+				Transcript: "hello world",
+				Words: []Words{
+					{Label: "hello", StartTime: 3100, EndTime: 3500},
+					{Label: "world", StartTime: 3600, EndTime: 4000},
+				},
+			},
+			{
+				StartTime:  9000,
+				EndTime:    10000,
+				Transcript: "没有词",
+			},
+		},
+	}
+
+	tests := []struct {
+		name string
+		opts SegmentOptions
+		want []Utterance
+	}{
+		{
+			name: "未设置条件",
+			opts: SegmentOptions{},
+			want: []Utterance{
+				{StartTime: 0, EndTime: 3000, Transcript: "今天天气很好"},
+				{StartTime: 3100, EndTime: 4000, Transcript: "hello world"},
+				{StartTime: 9000, EndTime: 10000, Transcript: "没有词"},
+			},
+		},
+		{
+			name: "按停顿断句",
+			opts: SegmentOptions{MaxGap: 1000},
+			want: []Utterance{
+				{StartTime: 0, EndTime: 1200, Transcript: "今天天气"},
+				{StartTime: 2500, EndTime: 4000, Transcript: "很好hello world"},
+				{StartTime: 9000, EndTime: 10000, Transcript: "没有词"},
+			},
+		},
+		{
+			name: "按时长断句",
+			opts: SegmentOptions{MaxDuration: 2000},
+			want: []Utterance{
+				{StartTime: 0, EndTime: 1200, Transcript: "今天天气"},
+				{StartTime: 2500, EndTime: 3000, Transcript: "很好"},
+				// 未设置 MaxGap 时不合并中间有停顿的句子
+				{StartTime: 3100, EndTime: 4000, Transcript: "hello world"},
+				{StartTime: 9000, EndTime: 10000, Transcript: "没有词"},
+			},
+		},
+		{
+			name: "按字数断句",
+			opts: SegmentOptions{MaxChars: 6},
+			want: []Utterance{
+				{StartTime: 0, EndTime: 3000, Transcript: "今天天气很好"},
+				{StartTime: 3100, EndTime: 3500, Transcript: "hello"},
+				{StartTime: 3600, EndTime: 4000, Transcript: "world"},
+				{StartTime: 9000, EndTime: 10000, Transcript: "没有词"},
+			},
+		},
+		{
+			name: "合并短句",
+			opts: SegmentOptions{MaxGap: 10000},
+			want: []Utterance{
+				{StartTime: 0, EndTime: 10000, Transcript: "今天天气很好hello world没有词"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := result.Resegment(tt.opts)
+			if len(got.Utterances) != len(tt.want) {
+				t.Fatalf("len(Utterances) = %d, want %d: %+v", len(got.Utterances), len(tt.want), got.Utterances)
+			}
+			for i, u := range got.Utterances {
+				w := tt.want[i]
+				if u.StartTime != w.StartTime || u.EndTime != w.EndTime || u.Transcript != w.Transcript {
+					t.Errorf("Utterances[%d] = {%d %d %q}, want {%d %d %q}",
+						i, u.StartTime, u.EndTime, u.Transcript, w.StartTime, w.EndTime, w.Transcript)
+				}
+			}
+		})
+	}
+}
+
+func TestASRResult_ResegmentNoGap(t *testing.T) {
+	result := &ASRResult{Utterances: []Utterance{
+		{StartTime: 0, EndTime: 1000, Transcript: "你好"},
+		{StartTime: 1000, EndTime: 1500, Transcript: "呀"},
+		{StartTime: 4000, EndTime: 4500, Transcript: "再见"},
+	}}
+
+	// 首尾相接的句子合并，中间有 2.5 秒静音的句子不合并
+	got := result.Resegment(SegmentOptions{MaxDuration: 5000})
+	want := []Utterance{
+		{StartTime: 0, EndTime: 1500, Transcript: "你好呀"},
+		{StartTime: 4000, EndTime: 4500, Transcript: "再见"},
+	}
+	if !reflect.DeepEqual(got.Utterances, want) {
+		t.Errorf("Resegment() = %+v, want %+v", got.Utterances, want)
+	}
+}
@@ -0,0 +1,94 @@
+package types
+
+import (
+	"sort"
+	"time"
+)
+
+// TimeSegment 裁剪后音频中连续的一段及其在原音频中的位置
+type TimeSegment struct {
+	Start  int64 // 在裁剪后音频中的开始时间（毫秒）
+	Source int64 // 在原音频中的开始时间（毫秒）
+	Length int64 // 时长（毫秒）
+}
+
+// TimeMap 裁剪后音频到原音频的时间映射，按 Start 升序排列
+//
+// 去除静音后上传的音频由原音频中保留的区间依次拼接而成，识别结果的时间需要映射回原音频才能与原媒体同步。
+// nil 表示未裁剪，时间不变。
+type TimeMap []TimeSegment
+
+// NewTimeMap 由原音频中依次保留的区间构造 TimeMap
+func NewTimeMap(kept []TimeRange) TimeMap {
+	m := make(TimeMap, 0, len(kept))
+	var pos time.Duration
+	for _, r := range kept {
+		if r.End <= r.Start {
+			continue
+		}
+		m = append(m, TimeSegment{
+			Start:  pos.Milliseconds(),
+			Source: r.Start.Milliseconds(),
+			Length: (r.End - r.Start).Milliseconds(),
+		})
+		pos += r.End - r.Start
+	}
+	return m
+}
+
+// Map 将裁剪后音频中的时间 t（毫秒）映射到原音频，位于两段交界处时映射到后一段的开始
+func (m TimeMap) Map(t int64) int64 {
+	i := sort.Search(len(m), func(i int) bool { return m[i].Start > t }) - 1
+	return m.mapIn(i, t)
+}
+
+// mapEnd 映射结束时间，位于两段交界处时映射到前一段的结束
+func (m TimeMap) mapEnd(t int64) int64 {
+	i := sort.Search(len(m), func(i int) bool { return m[i].Start >= t }) - 1
+	return m.mapIn(i, t)
+}
+
+// mapIn 按第 i 段映射 t，超出该段的部分截断到段尾，最后一段除外
+func (m TimeMap) mapIn(i int, t int64) int64 {
+	if len(m) == 0 {
+		return t
+	}
+	if i < 0 {
+		i = 0
+	}
+	offset := t - m[i].Start
+	if i+1 < len(m) && offset > m[i].Length {
+		offset = m[i].Length
+	}
+	return m[i].Source + offset
+}
+
+// Apply 返回时间映射到原音频后的结果，不修改 r；m 为空时直接返回 r
+func (m TimeMap) Apply(r *ASRResult) *ASRResult {
+	if len(m) == 0 || r == nil {
+		return r
+	}
+	mapped := &ASRResult{Version: r.Version, Utterances: make([]Utterance, len(r.Utterances))}
+	for i, u := range r.Utterances {
+		u.StartTime, u.EndTime = m.mapRange(u.StartTime, u.EndTime)
+		if u.Words != nil {
+			words := make([]Words, len(u.Words))
+			for k, w := range u.Words {
+				w.StartTime, w.EndTime = m.mapRange(w.StartTime, w.EndTime)
+				words[k] = w
+			}
+			u.Words = words
+		}
+		mapped.Utterances[i] = u
+	}
+	return mapped
+}
+
+// mapRange 映射一个时间区间，保证结束不早于开始
+func (m TimeMap) mapRange(start, end int64) (int64, int64) {
+	start, end = m.Map(start), m.mapEnd(end)
+	if end < start {
+		end = start
+	}
+	return start, end
+}
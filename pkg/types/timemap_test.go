@@ -0,0 +1,79 @@
+package types
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestTimeMap(t *testing.T) {
+	// 保留 1-3 秒和 10-12 秒，裁剪后为 0-2 秒和 2-4 秒
+	m := NewTimeMap([]TimeRange{
+		{Start: time.Second, End: 3 * time.Second},
+		{Start: 5 * time.Second, End: 5 * time.Second},
+		{Start: 10 * time.Second, End: 12 * time.Second},
+	})
+	want := TimeMap{
+		{Start: 0, Source: 1000, Length: 2000},
+		{Start: 2000, Source: 10000, Length: 2000},
+	}
+	if !reflect.DeepEqual(m, want) {
+		t.Fatalf("NewTimeMap() = %+v, want %+v", m, want)
+	}
+
+	tests := []struct {
+		t, start, end int64
+	}{
+		{0, 1000, 1000},
+		{500, 1500, 1500},
+		{2000, 10000, 3000},
+		{3500, 11500, 11500},
+		// 超出最后一段时按最后一段延伸
+		{4500, 12500, 12500},
+	}
+	for _, tt := range tests {
+		if got := m.Map(tt.t); got != tt.start {
+			t.Errorf("Map(%d) = %d, want %d", tt.t, got, tt.start)
+		}
+		if got := m.mapEnd(tt.t); got != tt.end {
+			t.Errorf("mapEnd(%d) = %d, want %d", tt.t, got, tt.end)
+		}
+	}
+
+	if got := TimeMap(nil).Map(1234); got != 1234 {
+		t.Errorf("nil Map() = %d", got)
+	}
+}
+
+func TestTimeMap_Apply(t *testing.T) {
+	m := NewTimeMap([]TimeRange{
+		{Start: 30 * time.Second, End: 40 * time.Second},
+		{Start: 100 * time.Second, End: 110 * time.Second},
+	})
+	r := &ASRResult{Version: "v1", Utterances: []Utterance{
+		{StartTime: 1000, EndTime: 10000, Transcript: "第一句"},
+		// 跨越裁剪点的句子，词分别映射到两段
+		{StartTime: 9000, EndTime: 12000, Transcript: "你好", Words: []Words{
+			{Label: "你", StartTime: 9000, EndTime: 10000},
+			{Label: "好", StartTime: 10000, EndTime: 12000},
+		}},
+	}}
+
+	got := m.Apply(r)
+	want := &ASRResult{Version: "v1", Utterances: []Utterance{
+		{StartTime: 31000, EndTime: 40000, Transcript: "第一句"},
+		{StartTime: 39000, EndTime: 102000, Transcript: "你好", Words: []Words{
+			{Label: "你", StartTime: 39000, EndTime: 40000},
+			{Label: "好", StartTime: 100000, EndTime: 102000},
+		}},
+	}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Apply() = %+v, want %+v", got, want)
+	}
+	if r.Utterances[1].Words[1].StartTime != 10000 {
+		t.Error("Apply() 不应修改输入的结果")
+	}
+	if TimeMap(nil).Apply(r) != r {
+		t.Error("nil Apply() 应返回原结果")
+	}
+}
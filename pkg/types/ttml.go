@@ -0,0 +1,367 @@
+package types
+
+import (
+	"bufio"
+	"encoding/xml"
+	"fmt"
+	"image/color"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// TTMLStyle TTML 字幕样式
+type TTMLStyle struct {
+	FontFamily      string      // 字体，默认 "proportionalSansSerif"
+	FontSize        string      // 字号，默认 "100%"
+	Color           color.NRGBA // 文字颜色
+	BackgroundColor color.NRGBA // 背景颜色，透明度为 0 时不显示背景
+	TextAlign       string      // 对齐方式，默认 "center"
+}
+
+// DefaultTTMLStyle 默认样式：白字半透明黑底，居中
+func DefaultTTMLStyle() TTMLStyle {
+	return TTMLStyle{
+		FontFamily:      "proportionalSansSerif",
+		FontSize:        "100%",
+		Color:           color.NRGBA{R: 0xff, G: 0xff, B: 0xff, A: 0xff},
+		BackgroundColor: color.NRGBA{A: 0xc2},
+		TextAlign:       "center",
+	}
+}
+
+// TTMLRegion TTML 字幕显示区域，使用相对画面的百分比
+type TTMLRegion struct {
+	Origin       string // 左上角位置，默认 "10% 80%"
+	Extent       string // 宽高，默认 "80% 15%"
+	DisplayAlign string // 垂直对齐，默认 "after"（底部）
+}
+
+// DefaultTTMLRegion 默认区域：画面底部
+func DefaultTTMLRegion() TTMLRegion {
+	return TTMLRegion{
+		Origin:       "10% 80%",
+		Extent:       "80% 15%",
+		DisplayAlign: "after",
+	}
+}
+
+// TTMLOptions TTML 输出选项
+//
+// FrameRate 和 TickRate 只用于 IMSC1：设置 TickRate 时时间以 tick 表示（如 "10010000t"），
+// 否则设置 FrameRate 时以帧表示（hh:mm:ss:ff）。EBU-TT-D 只允许 hh:mm:ss.sss，忽略这两项。
+type TTMLOptions struct {
+	Lang      string      // xml:lang，默认 "zh"
+	FrameRate int         // ttp:frameRate
+	TickRate  int         // ttp:tickRate
+	Region    *TTMLRegion // 显示区域，为 nil 时使用 DefaultTTMLRegion
+	Style     *TTMLStyle  // 字幕样式，为 nil 时使用 DefaultTTMLStyle
+}
+
+const (
+	imsc1TextProfile = "http://www.w3.org/ns/ttml/profile/imsc1/text"
+	ebuttdStandard   = "urn:ebu:tt:distribution:2014-01"
+)
+
+// ToTTML 将识别结果转换为 IMSC1 Text Profile 的 TTML 文档
+func (r *ASRResult) ToTTML(opts TTMLOptions) string {
+	var b strings.Builder
+	r.WriteTTML(&b, opts)
+	return b.String()
+}
+
+// ToEBUTTD 将识别结果转换为 EBU-TT-D 文档
+func (r *ASRResult) ToEBUTTD(opts TTMLOptions) string {
+	var b strings.Builder
+	r.WriteEBUTTD(&b, opts)
+	return b.String()
+}
+
+// WriteTTML 将 IMSC1 Text Profile 的 TTML 文档写入 w
+func (r *ASRResult) WriteTTML(w io.Writer, opts TTMLOptions) error {
+	return r.writeTTML(w, opts, false)
+}
+
+// WriteEBUTTD 将 EBU-TT-D 文档写入 w
+func (r *ASRResult) WriteEBUTTD(w io.Writer, opts TTMLOptions) error {
+	return r.writeTTML(w, opts, true)
+}
+
+func (r *ASRResult) writeTTML(w io.Writer, opts TTMLOptions, ebu bool) error {
+	if opts.Lang == "" {
+		opts.Lang = "zh"
+	}
+	if ebu {
+		opts.FrameRate, opts.TickRate = 0, 0
+	}
+	style := DefaultTTMLStyle()
+	if opts.Style != nil {
+		style = *opts.Style
+	}
+	region := DefaultTTMLRegion()
+	if opts.Region != nil {
+		region = *opts.Region
+	}
+
+	b := bufio.NewWriter(w)
+	b.WriteString(xml.Header)
+	b.WriteString(`<tt xmlns="http://www.w3.org/ns/ttml"` +
+		` xmlns:ttp="http://www.w3.org/ns/ttml#parameter"` +
+		` xmlns:tts="http://www.w3.org/ns/ttml#styling"` +
+		` xmlns:ttm="http://www.w3.org/ns/ttml#metadata"`)
+	if ebu {
+		b.WriteString(` xmlns:ebuttm="urn:ebu:tt:metadata"`)
+	} else {
+		fmt.Fprintf(b, ` ttp:profile="%s"`, imsc1TextProfile)
+	}
+	b.WriteString(` ttp:timeBase="media"`)
+	if opts.FrameRate > 0 {
+		fmt.Fprintf(b, ` ttp:frameRate="%d"`, opts.FrameRate)
+	}
+	if opts.TickRate > 0 {
+		fmt.Fprintf(b, ` ttp:tickRate="%d"`, opts.TickRate)
+	}
+	fmt.Fprintf(b, ` xml:lang="%s">`+"\n", escapeXML(opts.Lang))
+
+	b.WriteString("  <head>\n")
+	if ebu {
+		b.WriteString("    <metadata>\n")
+		b.WriteString("      <ebuttm:documentMetadata>\n")
+		fmt.Fprintf(b, "        <ebuttm:conformsToStandard>%s</ebuttm:conformsToStandard>\n", ebuttdStandard)
+		b.WriteString("      </ebuttm:documentMetadata>\n")
+		b.WriteString("    </metadata>\n")
+	}
+	b.WriteString("    <styling>\n")
+	fmt.Fprintf(b, `      <style xml:id="s1" tts:fontFamily="%s" tts:fontSize="%s" tts:color="%s"`,
+		escapeXML(style.FontFamily), escapeXML(style.FontSize), ttmlColor(style.Color))
+	if style.BackgroundColor.A > 0 {
+		fmt.Fprintf(b, ` tts:backgroundColor="%s"`, ttmlColor(style.BackgroundColor))
+	}
+	fmt.Fprintf(b, ` tts:textAlign="%s"/>`+"\n", escapeXML(style.TextAlign))
+	b.WriteString("    </styling>\n")
+	b.WriteString("    <layout>\n")
+	fmt.Fprintf(b, `      <region xml:id="r1" tts:origin="%s" tts:extent="%s" tts:displayAlign="%s"/>`+"\n",
+		escapeXML(region.Origin), escapeXML(region.Extent), escapeXML(region.DisplayAlign))
+	b.WriteString("    </layout>\n")
+	b.WriteString("  </head>\n")
+
+	b.WriteString("  <body>\n")
+	b.WriteString(`    <div region="r1">` + "\n")
+	for i, u := range r.Utterances {
+		fmt.Fprintf(b, `      <p xml:id="sub%d" begin="%s" end="%s"><span style="s1">%s</span></p>`+"\n",
+			i+1,
+			formatTTMLTimestamp(u.StartTime, opts),
+			formatTTMLTimestamp(u.EndTime, opts),
+			escapeXML(u.Transcript))
+	}
+	b.WriteString("    </div>\n")
+	b.WriteString("  </body>\n")
+	b.WriteString("</tt>\n")
+	return b.Flush()
+}
+
+func escapeXML(s string) string {
+	var b strings.Builder
+	xml.EscapeText(&b, []byte(s))
+	return b.String()
+}
+
+// ttmlColor 转换为 #RRGGBBAA
+func ttmlColor(c color.NRGBA) string {
+	return fmt.Sprintf("#%02X%02X%02X%02X", c.R, c.G, c.B, c.A)
+}
+
+func formatTTMLTimestamp(ts int64, opts TTMLOptions) string {
+	switch {
+	case opts.TickRate > 0:
+		return fmt.Sprintf("%dt", ts*int64(opts.TickRate)/1000)
+	case opts.FrameRate > 0:
+		return fmt.Sprintf("%02d:%02d:%02d:%02d",
+			ts/3600000, (ts/60000)%60, (ts/1000)%60, (ts%1000)*int64(opts.FrameRate)/1000)
+	default:
+		return fmt.Sprintf("%02d:%02d:%02d.%03d",
+			ts/3600000, (ts/60000)%60, (ts/1000)%60, ts%1000)
+	}
+}
+
+const ttmlParameterNS = "http://www.w3.org/ns/ttml#parameter"
+
+// ParseTTML 解析 TTML 字幕（包括 IMSC1 和 EBU-TT-D），每个 <p> 为一句
+//
+// 支持时钟时间（hh:mm:ss.fff、hh:mm:ss:ff）和偏移时间（如 1.5s、90f、10010000t），
+// 只使用 <p> 自身的 begin、end 和 dur，不计算外层元素的时间。
+func ParseTTML(r io.Reader) (*ASRResult, error) {
+	var (
+		dec    = xml.NewDecoder(r)
+		result = &ASRResult{}
+		timing = ttmlTiming{frameRate: 30, tickRate: 1}
+		inP    bool
+		cur    Utterance
+		lines  []string
+		line   strings.Builder
+	)
+	for {
+		tok, err := dec.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("解析TTML失败: %w", err)
+		}
+
+		switch t := tok.(type) {
+		case xml.StartElement:
+			switch t.Name.Local {
+			case "tt":
+				timing = parseTTMLTiming(t.Attr)
+			case "p":
+				begin, end, err := timing.span(t.Attr)
+				if err != nil {
+					return nil, err
+				}
+				inP = true
+				cur = Utterance{StartTime: begin, EndTime: end}
+				lines = lines[:0]
+				line.Reset()
+			case "br":
+				if inP {
+					lines = append(lines, line.String())
+					line.Reset()
+				}
+			}
+		case xml.EndElement:
+			if t.Name.Local == "p" && inP {
+				lines = append(lines, line.String())
+				for i, l := range lines {
+					lines[i] = strings.Join(strings.Fields(l), " ")
+				}
+				cur.Transcript = strings.Join(lines, "\n")
+				result.Utterances = append(result.Utterances, cur)
+				inP = false
+			}
+		case xml.CharData:
+			if inP {
+				line.Write(t)
+			}
+		}
+	}
+	return result, nil
+}
+
+// ttmlTiming 文档的时间参数
+type ttmlTiming struct {
+	frameRate int64
+	tickRate  int64
+}
+
+func parseTTMLTiming(attrs []xml.Attr) ttmlTiming {
+	timing := ttmlTiming{frameRate: 30}
+	frameRateSet := false
+	for _, attr := range attrs {
+		if attr.Name.Space != ttmlParameterNS {
+			continue
+		}
+		n, err := strconv.ParseInt(strings.TrimSpace(attr.Value), 10, 64)
+		if err != nil || n <= 0 {
+			continue
+		}
+		switch attr.Name.Local {
+		case "frameRate":
+			timing.frameRate = n
+			frameRateSet = true
+		case "tickRate":
+			timing.tickRate = n
+		}
+	}
+	// 未设置 tickRate 时，设置了 frameRate 则与帧率相同，否则为 1
+	if timing.tickRate == 0 {
+		timing.tickRate = 1
+		if frameRateSet {
+			timing.tickRate = timing.frameRate
+		}
+	}
+	return timing
+}
+
+// span 解析元素的 begin、end 和 dur
+func (t ttmlTiming) span(attrs []xml.Attr) (int64, int64, error) {
+	var begin, end, dur int64 = 0, -1, -1
+	for _, attr := range attrs {
+		var (
+			ms  int64
+			err error
+		)
+		switch attr.Name.Local {
+		case "begin", "end", "dur":
+			if ms, err = t.parse(attr.Value); err != nil {
+				return 0, 0, err
+			}
+		default:
+			continue
+		}
+		switch attr.Name.Local {
+		case "begin":
+			begin = ms
+		case "end":
+			end = ms
+		case "dur":
+			dur = ms
+		}
+	}
+	if end < 0 {
+		end = begin
+		if dur >= 0 {
+			end = begin + dur
+		}
+	}
+	return begin, end, nil
+}
+
+// parse 解析时间表达式为毫秒
+func (t ttmlTiming) parse(expr string) (int64, error) {
+	expr = strings.TrimSpace(expr)
+	if strings.Contains(expr, ":") {
+		parts := strings.Split(expr, ":")
+		if len(parts) == 4 {
+			// hh:mm:ss:ff，忽略子帧
+			frames, err := strconv.ParseInt(strings.SplitN(parts[3], ".", 2)[0], 10, 64)
+			if err != nil {
+				return 0, fmt.Errorf("无效的时间: %q", expr)
+			}
+			ms, err := parseClock(strings.Join(parts[:3], ":"))
+			if err != nil {
+				return 0, err
+			}
+			return ms + frames*1000/t.frameRate, nil
+		}
+		return parseClock(expr)
+	}
+
+	// 偏移时间：数值加单位
+	unit := strings.TrimLeft(expr, "0123456789.")
+	value, err := strconv.ParseFloat(expr[:len(expr)-len(unit)], 64)
+	if err != nil {
+		return 0, fmt.Errorf("无效的时间: %q", expr)
+	}
+	switch unit {
+	case "h":
+		return roundMS(value * 3600000), nil
+	case "m":
+		return roundMS(value * 60000), nil
+	case "s":
+		return roundMS(value * 1000), nil
+	case "ms":
+		return roundMS(value), nil
+	case "f":
+		return roundMS(value * 1000 / float64(t.frameRate)), nil
+	case "t":
+		return roundMS(value * 1000 / float64(t.tickRate)), nil
+	default:
+		return 0, fmt.Errorf("无效的时间: %q", expr)
+	}
+}
+
+func roundMS(ms float64) int64 {
+	return int64(math.Round(ms))
+}
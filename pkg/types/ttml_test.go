@@ -0,0 +1,88 @@
+package types
+
+import (
+	"encoding/xml"
+	"strings"
+	"testing"
+)
+
+func TestASRResult_ToTTML(t *testing.T) {
+	result := &ASRResult{
+		Utterances: []Utterance{
+			{StartTime: 1000, EndTime: 2500, Transcript: "Tom & Jerry <1>"},
+			{StartTime: 3723040, EndTime: 3724000, Transcript: "测试字幕"},
+		},
+	}
+
+	tests := []struct {
+		name   string
+		output string
+		want   []string
+		absent []string
+	}{
+		{
+			name:   "IMSC1",
+			output: result.ToTTML(TTMLOptions{}),
+			want: []string{
+				`ttp:profile="http://www.w3.org/ns/ttml/profile/imsc1/text"`,
+				`xml:lang="zh"`,
+				`<style xml:id="s1" tts:fontFamily="proportionalSansSerif" tts:fontSize="100%" tts:color="#FFFFFFFF" tts:backgroundColor="#000000C2" tts:textAlign="center"/>`,
+				`<region xml:id="r1" tts:origin="10% 80%" tts:extent="80% 15%" tts:displayAlign="after"/>`,
+				`<p xml:id="sub1" begin="00:00:01.000" end="00:00:02.500"><span style="s1">Tom &amp; Jerry &lt;1&gt;</span></p>`,
+				`<p xml:id="sub2" begin="01:02:03.040" end="01:02:04.000">`,
+			},
+			absent: []string{"ebuttm", "ttp:frameRate", "ttp:tickRate"},
+		},
+		{
+			name:   "帧",
+			output: result.ToTTML(TTMLOptions{FrameRate: 25, Lang: "en"}),
+			want: []string{
+				`ttp:frameRate="25"`,
+				`xml:lang="en"`,
+				`begin="00:00:01:00" end="00:00:02:12"`,
+				`begin="01:02:03:01" end="01:02:04:00"`,
+			},
+		},
+		{
+			name:   "tick",
+			output: result.ToTTML(TTMLOptions{TickRate: 10000000}),
+			want: []string{
+				`ttp:tickRate="10000000"`,
+				`begin="10000000t" end="25000000t"`,
+			},
+		},
+		{
+			name: "EBU-TT-D",
+			output: result.ToEBUTTD(TTMLOptions{
+				FrameRate: 25,
+				Region:    &TTMLRegion{Origin: "5% 5%", Extent: "90% 20%", DisplayAlign: "before"},
+				Style:     &TTMLStyle{FontFamily: "Arial", FontSize: "120%", TextAlign: "left"},
+			}),
+			want: []string{
+				`<ebuttm:conformsToStandard>urn:ebu:tt:distribution:2014-01</ebuttm:conformsToStandard>`,
+				`<style xml:id="s1" tts:fontFamily="Arial" tts:fontSize="120%" tts:color="#00000000" tts:textAlign="left"/>`,
+				`<region xml:id="r1" tts:origin="5% 5%" tts:extent="90% 20%" tts:displayAlign="before"/>`,
+				`begin="00:00:01.000" end="00:00:02.500"`,
+			},
+			absent: []string{"ttp:profile", "ttp:frameRate", "backgroundColor"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := xml.Unmarshal([]byte(tt.output), new(struct{})); err != nil {
+				t.Fatalf("输出不是合法的XML: %v\n%s", err, tt.output)
+			}
+			for _, want := range tt.want {
+				if !strings.Contains(tt.output, want) {
+					t.Errorf("输出缺少 %q\n%s", want, tt.output)
+				}
+			}
+			for _, absent := range tt.absent {
+				if strings.Contains(tt.output, absent) {
+					t.Errorf("输出不应包含 %q\n%s", absent, tt.output)
+				}
+			}
+		})
+	}
+}
@@ -1,7 +1,11 @@
 package types
 
 import (
+	"bufio"
+	"encoding/json"
 	"fmt"
+	"io"
+	"strings"
 	"sync"
 )
 
@@ -32,8 +36,13 @@ const (
 )
 
 var (
-	SupportedInputFormats  = []string{"flac", "aac", "m4a", "mp3", "wav"}
-	SupportedOutputFormats = []string{"srt", "json", "lrc", "txt"}
+	SupportedInputFormats = []string{"flac", "aac", "m4a", "mp3", "wav"}
+	// SupportedOutputFormats 内置的输出格式
+	//
+	// 只列出内置格式，不随 RegisterFormatter 和 UnregisterFormatter 变化。
+	//
+	// Deprecated: 使用 OutputFormats 获取当前已注册的全部格式。
+	SupportedOutputFormats = []string{"srt", "json", "lrc", "txt", "vtt", "ass", "ttml", "ebuttd"}
 )
 
 type ResultState int
@@ -108,34 +117,48 @@ type TaskResultResponse struct {
 
 // ToSRT 将识别结果转换为SRT格式
 func (r *ASRResult) ToSRT() string {
-	var result string
+	var b strings.Builder
+	r.WriteSRT(&b)
+	return b.String()
+}
+
+// WriteSRT 将SRT格式的识别结果写入 w
+func (r *ASRResult) WriteSRT(w io.Writer) error {
+	b := bufio.NewWriter(w)
 	for i, u := range r.Utterances {
-		result += fmt.Sprintf("%d\n%s\n%s\n\n",
+		fmt.Fprintf(b, "%d\n%s\n%s\n\n",
 			i+1,
 			formatSRTTimestamp(u.StartTime, u.EndTime),
 			u.Transcript)
 	}
-	return result
+	return b.Flush()
+}
+
+// ToTXT 将识别结果转换为纯文本格式
+func (r *ASRResult) ToTXT() string {
+	var b strings.Builder
+	r.WriteTXT(&b)
+	return b.String()
 }
 
-// ToLRC 将识别结果转换为LRC格式
-func (r *ASRResult) ToLRC() string {
-	var result string
+// WriteTXT 将纯文本格式的识别结果写入 w
+func (r *ASRResult) WriteTXT(w io.Writer) error {
+	b := bufio.NewWriter(w)
 	for _, u := range r.Utterances {
-		result += fmt.Sprintf("[%s]%s\n",
-			formatLRCTimestamp(u.StartTime),
-			u.Transcript)
+		b.WriteString(u.Transcript)
+		b.WriteByte('\n')
 	}
-	return result
+	return b.Flush()
 }
 
-// ToTXT 将识别结果转换为纯文本格式
-func (r *ASRResult) ToTXT() string {
-	var result string
-	for _, u := range r.Utterances {
-		result += u.Transcript + "\n"
+// WriteJSON 将JSON格式的识别结果写入 w
+func (r *ASRResult) WriteJSON(w io.Writer) error {
+	data, err := json.MarshalIndent(r, "", "  ")
+	if err != nil {
+		return fmt.Errorf("JSON序列化失败: %w", err)
 	}
-	return result
+	_, err = w.Write(data)
+	return err
 }
 
 func formatSRTTimestamp(start, end int64) string {
@@ -144,11 +167,6 @@ func formatSRTTimestamp(start, end int64) string {
 		end/3600000, (end/60000)%60, (end/1000)%60, end%1000)
 }
 
-func formatLRCTimestamp(ts int64) string {
-	return fmt.Sprintf("%02d:%02d.%02d",
-		ts/60000, (ts/1000)%60, (ts%1000)/10)
-}
-
 // ProgressStage 进度阶段
 type ProgressStage string
 
@@ -1,6 +1,10 @@
 package types
 
 import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
 	"testing"
 )
 
@@ -69,3 +73,63 @@ func TestASRResult_ToTXT(t *testing.T) {
 		t.Errorf("ToTXT() = %v, want %v", got, expected)
 	}
 }
+
+// failWriter 写入指定字节数后返回错误
+type failWriter struct {
+	n int
+}
+
+var errWrite = errors.New("write failed")
+
+func (w *failWriter) Write(p []byte) (int, error) {
+	if len(p) > w.n {
+		n := w.n
+		w.n = 0
+		return n, errWrite
+	}
+	w.n -= len(p)
+	return len(p), nil
+}
+
+func TestASRResult_Write(t *testing.T) {
+	result := &ASRResult{}
+	for i := 0; i < 20000; i++ {
+		result.Utterances = append(result.Utterances, Utterance{
+			StartTime:  int64(i) * 1000,
+			EndTime:    int64(i)*1000 + 900,
+			Transcript: fmt.Sprintf("测试字幕%d", i),
+			Words:      []Words{{Label: fmt.Sprintf("测试字幕%d", i), StartTime: int64(i) * 1000, EndTime: int64(i)*1000 + 900}},
+		})
+	}
+
+	tests := []struct {
+		name  string
+		write func(io.Writer) error
+		str   func() string
+	}{
+		{"srt", result.WriteSRT, result.ToSRT},
+		{"txt", result.WriteTXT, result.ToTXT},
+		{"lrc", func(w io.Writer) error { return result.WriteLRC(w, LRCOptions{WordTimestamps: true}) },
+			func() string { return result.ToLRCWithOptions(LRCOptions{WordTimestamps: true}) }},
+		{"vtt", func(w io.Writer) error { return result.WriteVTT(w, VTTOptions{}) }, result.ToVTT},
+		{"ass", func(w io.Writer) error { return result.WriteASS(w, ASSOptions{}) }, result.ToASS},
+		{"ttml", func(w io.Writer) error { return result.WriteTTML(w, TTMLOptions{}) },
+			func() string { return result.ToTTML(TTMLOptions{}) }},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			if err := tt.write(&buf); err != nil {
+				t.Fatalf("Write error = %v", err)
+			}
+			if buf.String() != tt.str() {
+				t.Error("Write 与字符串输出不一致")
+			}
+
+			if err := tt.write(&failWriter{n: buf.Len() / 2}); !errors.Is(err, errWrite) {
+				t.Errorf("写入失败时 error = %v, want %v", err, errWrite)
+			}
+		})
+	}
+}
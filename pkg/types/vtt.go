@@ -0,0 +1,151 @@
+package types
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"html"
+	"io"
+	"strings"
+)
+
+// VTTOptions WebVTT 输出选项
+type VTTOptions struct {
+	WordTimestamps bool // 在字幕文本中插入 <hh:mm:ss.mmm> 词级时间戳，用于逐词高亮
+}
+
+// ToVTT 将识别结果转换为WebVTT格式
+func (r *ASRResult) ToVTT() string {
+	return r.ToVTTWithOptions(VTTOptions{})
+}
+
+// ToVTTWithOptions 按选项将识别结果转换为WebVTT格式
+func (r *ASRResult) ToVTTWithOptions(opts VTTOptions) string {
+	var b strings.Builder
+	r.WriteVTT(&b, opts)
+	return b.String()
+}
+
+// WriteVTT 将WebVTT格式的识别结果写入 w
+func (r *ASRResult) WriteVTT(w io.Writer, opts VTTOptions) error {
+	b := bufio.NewWriter(w)
+	b.WriteString("WEBVTT\n\n")
+	for i, u := range r.Utterances {
+		fmt.Fprintf(b, "%d\n%s --> %s\n%s\n\n",
+			i+1,
+			formatVTTTimestamp(u.StartTime),
+			formatVTTTimestamp(u.EndTime),
+			vttCueText(u, opts))
+	}
+	return b.Flush()
+}
+
+// vttCueText 生成字幕文本，词级时间戳只能位于字幕开始和结束时间之间
+func vttCueText(u Utterance, opts VTTOptions) string {
+	if !opts.WordTimestamps || len(u.Words) == 0 {
+		return escapeVTT(u.Transcript)
+	}
+
+	var (
+		b    strings.Builder
+		prev string
+	)
+	for _, w := range u.Words {
+		if needSpace(prev, w.Label) {
+			b.WriteByte(' ')
+		}
+		if w.StartTime > u.StartTime && w.StartTime < u.EndTime {
+			fmt.Fprintf(&b, "<%s>", formatVTTTimestamp(w.StartTime))
+		}
+		b.WriteString(escapeVTT(w.Label))
+		prev = w.Label
+	}
+	return b.String()
+}
+
+// escapeVTT 转义字幕文本中的特殊字符
+func escapeVTT(s string) string {
+	return strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;").Replace(s)
+}
+
+func formatVTTTimestamp(ts int64) string {
+	return fmt.Sprintf("%02d:%02d:%02d.%03d",
+		ts/3600000, (ts/60000)%60, (ts/1000)%60, ts%1000)
+}
+
+// ParseVTT 解析WebVTT字幕，字幕文本中的 <hh:mm:ss.mmm> 时间标签还原为词级时间戳
+func ParseVTT(r io.Reader) (*ASRResult, error) {
+	lines, err := readLines(r)
+	if err != nil {
+		return nil, err
+	}
+	if len(lines) == 0 || !strings.HasPrefix(lines[0], "WEBVTT") {
+		return nil, errors.New("缺少 WEBVTT 文件头")
+	}
+
+	result := &ASRResult{}
+	for i := 1; i < len(lines); i++ {
+		line := lines[i]
+		// 跳过注释、样式和区域定义块
+		if strings.HasPrefix(line, "NOTE") || strings.HasPrefix(line, "STYLE") || strings.HasPrefix(line, "REGION") {
+			for i+1 < len(lines) && strings.TrimSpace(lines[i+1]) != "" {
+				i++
+			}
+			continue
+		}
+		if !strings.Contains(line, "-->") {
+			continue
+		}
+		start, end, err := parseTimeRange(line)
+		if err != nil {
+			return nil, fmt.Errorf("第 %d 行: %w", i+1, err)
+		}
+		var text []string
+		for i+1 < len(lines) && strings.TrimSpace(lines[i+1]) != "" {
+			i++
+			text = append(text, lines[i])
+		}
+
+		transcript, words := parseVTTText(strings.Join(text, "\n"), start, end)
+		result.Utterances = append(result.Utterances, Utterance{
+			StartTime:  start,
+			EndTime:    end,
+			Transcript: transcript,
+			Words:      words,
+		})
+	}
+	return result, nil
+}
+
+// parseVTTText 去掉字幕文本中的标签，时间标签作为词的分界
+func parseVTTText(text string, start, end int64) (string, []Words) {
+	segments := []timedSegment{{start: start, end: -1}}
+	var transcript strings.Builder
+	for text != "" {
+		i := strings.IndexByte(text, '<')
+		if i < 0 {
+			i = len(text)
+		}
+		plain := html.UnescapeString(text[:i])
+		segments[len(segments)-1].text += plain
+		transcript.WriteString(plain)
+		text = text[i:]
+		if text == "" {
+			break
+		}
+
+		j := strings.IndexByte(text, '>')
+		if j < 0 {
+			break
+		}
+		if ts, err := parseClock(text[1:j]); err == nil {
+			segments = append(segments, timedSegment{start: ts, end: -1})
+		}
+		text = text[j+1:]
+	}
+
+	if len(segments) == 1 {
+		return transcript.String(), nil
+	}
+	return transcript.String(), segmentsToWords(segments, end)
+}
@@ -0,0 +1,58 @@
+package types
+
+import (
+	"testing"
+)
+
+func TestASRResult_ToVTT(t *testing.T) {
+	result := &ASRResult{
+		Utterances: []Utterance{
+			{
+				StartTime:  1000,
+				EndTime:    2500,
+				Transcript: "hello <world>",
+				Words: []Words{
+					{Label: "hello", StartTime: 1000, EndTime: 1500},
+					{Label: "world", StartTime: 1600, EndTime: 2500},
+				},
+			},
+			{
+				StartTime:  3723004,
+				EndTime:    3724000,
+				Transcript: "测试字幕",
+				Words: []Words{
+					{Label: "测试", StartTime: 3723004, EndTime: 3723500},
+					{Label: "字幕", StartTime: 3723500, EndTime: 3724000},
+				},
+			},
+		},
+	}
+
+	tests := []struct {
+		name string
+		opts VTTOptions
+		want string
+	}{
+		{
+			name: "默认",
+			want: "WEBVTT\n\n" +
+				"1\n00:00:01.000 --> 00:00:02.500\nhello &lt;world&gt;\n\n" +
+				"2\n01:02:03.004 --> 01:02:04.000\n测试字幕\n\n",
+		},
+		{
+			name: "词级时间戳",
+			opts: VTTOptions{WordTimestamps: true},
+			want: "WEBVTT\n\n" +
+				"1\n00:00:01.000 --> 00:00:02.500\nhello <00:00:01.600>world\n\n" +
+				"2\n01:02:03.004 --> 01:02:04.000\n测试<01:02:03.500>字幕\n\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := result.ToVTTWithOptions(tt.opts); got != tt.want {
+				t.Errorf("ToVTTWithOptions() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -3,9 +3,18 @@
 
 package utils
 
-import "os/exec"
+import (
+	"context"
+	"os/exec"
+)
 
 func RunCommand(name string, arg ...string) *exec.Cmd {
 	cmd := exec.Command(name, arg...)
 	return cmd
 }
+
+// RunCommandContext 与 RunCommand 相同，ctx 取消时结束进程
+func RunCommandContext(ctx context.Context, name string, arg ...string) *exec.Cmd {
+	cmd := exec.CommandContext(ctx, name, arg...)
+	return cmd
+}
@@ -4,15 +4,27 @@
 package utils
 
 import (
+	"context"
 	"os/exec"
 	"syscall"
 )
 
 func RunCommand(name string, arg ...string) *exec.Cmd {
 	cmd := exec.Command(name, arg...)
+	hideWindow(cmd)
+	return cmd
+}
+
+// RunCommandContext 与 RunCommand 相同，ctx 取消时结束进程
+func RunCommandContext(ctx context.Context, name string, arg ...string) *exec.Cmd {
+	cmd := exec.CommandContext(ctx, name, arg...)
+	hideWindow(cmd)
+	return cmd
+}
+
+func hideWindow(cmd *exec.Cmd) {
 	cmd.SysProcAttr = &syscall.SysProcAttr{
 		HideWindow:    true,
 		CreationFlags: 0x08000000,
 	}
-	return cmd
 }